@@ -0,0 +1,117 @@
+package catalogue
+
+// Conditions of the blocks that enclose an instruction
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/decompiler"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// unreachableCondition is the condition of instructions in a switch block before the first case
+const unreachableCondition = "false"
+
+// conditionWalker collects the instructions of the decompiled functions of a room
+type conditionWalker struct {
+	room                Room
+	fileProgramCounters map[string][]int
+	instructions        []scriptInstruction
+}
+
+// walk visits the instructions of a block. The conditions are the expressions of every
+// enclosing if, else, while and case block that have to be true to reach the block.
+func (walker *conditionWalker) walk(nodes []*decompiler.Node, init bool, conditions []string) {
+	for _, node := range nodes {
+		// The condition opcodes of a block are checked before entering it
+		for _, condition := range node.Conditions {
+			walker.add(condition, init, conditions)
+		}
+
+		switch node.Kind {
+		case decompiler.NodeInstruction:
+			walker.add(node.Instruction, init, conditions)
+		case decompiler.NodeIf:
+			condition := decompiler.FormatCondition(node.Conditions, walker.room.RDT)
+			walker.walk(node.Body, init, appendCondition(conditions, condition))
+			walker.walk(node.Else, init, appendCondition(conditions, "!("+condition+")"))
+		case decompiler.NodeWhile:
+			condition := decompiler.FormatCondition(node.Conditions, walker.room.RDT)
+			walker.walk(node.Body, init, appendCondition(conditions, condition))
+		case decompiler.NodeFor, decompiler.NodeDo:
+			// The body runs at least once
+			walker.walk(node.Body, init, conditions)
+		case decompiler.NodeSwitch:
+			walker.walkSwitch(node, init, conditions)
+		}
+	}
+}
+
+func (walker *conditionWalker) add(instruction decompiler.Instruction, init bool, conditions []string) {
+	walker.instructions = append(walker.instructions, scriptInstruction{
+		instruction: instruction,
+		source:      walker.source(init, instruction.ProgramCounter),
+		condition:   joinConditions(conditions),
+	})
+}
+
+func (walker *conditionWalker) walkSwitch(node *decompiler.Node, init bool, conditions []string) {
+	instr, ok := node.Instruction.Decoded.(fileio.ScriptInstrSwitch)
+	if !ok {
+		return
+	}
+	variable := fmt.Sprintf("var[%d]", instr.VarId)
+
+	caseConditions := make([]string, 0)
+	for _, switchCase := range node.Cases {
+		if caseInstr, ok := switchCase.Instruction.Decoded.(fileio.ScriptInstrSwitchCase); ok {
+			caseConditions = append(caseConditions, fmt.Sprintf("%s == %d", variable, caseInstr.Value))
+		}
+	}
+
+	for _, switchCase := range node.Cases {
+		switch caseInstr := switchCase.Instruction.Decoded.(type) {
+		case fileio.ScriptInstrSwitchCase:
+			condition := fmt.Sprintf("%s == %d", variable, caseInstr.Value)
+			walker.walk(switchCase.Body, init, appendCondition(conditions, condition))
+		case fileio.ScriptInstrDefault:
+			defaultConditions := conditions
+			for _, caseCondition := range caseConditions {
+				defaultConditions = appendCondition(defaultConditions, "!("+caseCondition+")")
+			}
+			walker.walk(switchCase.Body, init, defaultConditions)
+		default:
+			walker.walk(switchCase.Body, init, appendCondition(conditions, unreachableCondition))
+		}
+	}
+}
+
+// source returns the script file and line of an instruction
+func (walker *conditionWalker) source(init bool, programCounter int) Source {
+	source := Source{Filename: walker.room.Filename}
+	for _, filename := range fileio.SortedScriptFilenames(walker.fileProgramCounters) {
+		if (filename == "init.scd") != init {
+			continue
+		}
+		programCounters := walker.fileProgramCounters[filename]
+		line := sort.SearchInts(programCounters, programCounter)
+		if line < len(programCounters) && programCounters[line] == programCounter {
+			source.ScriptFile, source.Line = filename, line+1
+			break
+		}
+	}
+	return source
+}
+
+// appendCondition returns a copy of the conditions with another condition
+func appendCondition(conditions []string, condition string) []string {
+	return append(append(make([]string, 0, len(conditions)+1), conditions...), condition)
+}
+
+// joinConditions combines the conditions of the enclosing blocks into one expression,
+// which is empty if the instruction always runs
+func joinConditions(conditions []string) string {
+	return strings.Join(conditions, " && ")
+}
@@ -0,0 +1,126 @@
+package catalogue
+
+// Room graph built from the door AOTs of every room
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Door is a door AOT that leads from one room to another
+type Door struct {
+	From         string   `json:"from"` // room id, e.g. 100
+	To           string   `json:"to"`
+	Player       uint8    `json:"player"`
+	Aot          uint8    `json:"aot"`
+	Camera       uint8    `json:"camera"`       // camera after the door was entered
+	NextPosition [3]int16 `json:"nextPosition"` // position of the player after the door was entered
+	NextDir      int16    `json:"nextDir"`
+	DoorType     uint8    `json:"doorType"` // animation played when the door is entered
+	KeyId        uint8    `json:"keyId"`    // item needed to unlock the door, 0 if the door is not locked
+	KeyType      uint8    `json:"keyType"`
+	Source       Source   `json:"source"`
+}
+
+// DoorGraph is the graph of every room and the doors between them
+type DoorGraph struct {
+	Rooms []string `json:"rooms"` // rooms that were loaded
+	Doors []Door   `json:"doors"`
+}
+
+// DoorOpcodes are the opcodes that place the doors found by FindDoors
+var DoorOpcodes = []byte{fileio.OP_DOOR_AOT_SET, fileio.OP_DOOR_AOT_SET_4P}
+
+// FindDoors returns every door placed by the scripts of a room
+func FindDoors(room Room) []Door {
+	doors := make([]Door, 0)
+	for _, line := range scriptInstructions(room) {
+		door := Door{From: room.Id, Player: room.Player, Source: line.source}
+		switch instr := line.instruction.Decoded.(type) {
+		case fileio.ScriptInstrDoorAotSet:
+			door.To = fileio.RoomId(instr.Stage, instr.Room)
+			door.Aot, door.Camera = instr.Aot, instr.Camera
+			door.NextPosition, door.NextDir = [3]int16{instr.NextX, instr.NextY, instr.NextZ}, instr.NextDir
+			door.DoorType, door.KeyId, door.KeyType = instr.DoorType, instr.KeyId, instr.KeyType
+		case fileio.ScriptInstrDoorAotSet4p:
+			door.To = fileio.RoomId(instr.Stage, instr.Room)
+			door.Aot, door.Camera = instr.Aot, instr.Camera
+			door.NextPosition, door.NextDir = [3]int16{instr.NextX, instr.NextY, instr.NextZ}, instr.NextDir
+			door.DoorType, door.KeyId, door.KeyType = instr.DoorType, instr.KeyId, instr.KeyType
+		default:
+			continue
+		}
+		doors = append(doors, door)
+	}
+	return doors
+}
+
+// NewDoorGraph collects the doors of every room
+func NewDoorGraph(rooms []Room) *DoorGraph {
+	graph := &DoorGraph{Rooms: make([]string, 0), Doors: make([]Door, 0)}
+	loaded := make(map[string]bool)
+	for _, room := range rooms {
+		if !loaded[room.Id] {
+			loaded[room.Id] = true
+			graph.Rooms = append(graph.Rooms, room.Id)
+		}
+		graph.Doors = append(graph.Doors, FindDoors(room)...)
+	}
+	sort.Strings(graph.Rooms)
+	return graph
+}
+
+// edgeLabel describes how a door is opened
+func (door Door) edgeLabel() string {
+	parts := make([]string, 0, 2)
+	if door.KeyId != 0 {
+		parts = append(parts, fmt.Sprintf("key %d", door.KeyId))
+	}
+	parts = append(parts, fmt.Sprintf("door type %d", door.DoorType))
+	return strings.Join(parts, "\n")
+}
+
+// WriteDOT writes the graph in the Graphviz DOT format.
+// Doors with the same rooms, key and door type are drawn as a single edge, e.g. when both
+// players or both branches of an if block place the same door. Rooms that are the target
+// of a door but were not loaded are drawn dashed.
+func (graph *DoorGraph) WriteDOT(w io.Writer) error {
+	var builder strings.Builder
+	builder.WriteString("digraph rooms {\n")
+	builder.WriteString("\tnode [shape=box];\n")
+
+	loaded := make(map[string]bool)
+	for _, room := range graph.Rooms {
+		loaded[room] = true
+		builder.WriteString(fmt.Sprintf("\t%q;\n", room))
+	}
+	missing := make(map[string]bool)
+	for _, door := range graph.Doors {
+		if !loaded[door.To] && !missing[door.To] {
+			missing[door.To] = true
+			builder.WriteString(fmt.Sprintf("\t%q [style=dashed];\n", door.To))
+		}
+	}
+
+	written := make(map[string]bool)
+	for _, door := range graph.Doors {
+		edge := fmt.Sprintf("\t%q -> %q [label=%q];\n", door.From, door.To, door.edgeLabel())
+		if !written[edge] {
+			written[edge] = true
+			builder.WriteString(edge)
+		}
+	}
+	builder.WriteString("}\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// WriteJSON writes every room and door with the script line that placed it
+func (graph *DoorGraph) WriteJSON(w io.Writer) error {
+	return writeJSON(w, graph)
+}
@@ -0,0 +1,121 @@
+package catalogue
+
+// Catalogue of the enemies spawned in every room
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Enemy is an enemy spawned by SceEmSet
+type Enemy struct {
+	Room      string `json:"room"` // room id, e.g. 100
+	Player    uint8  `json:"player"`
+	Aot       uint8  `json:"aot"`
+	Id        uint8  `json:"id"`
+	Type      uint8  `json:"type"`
+	Status    uint8  `json:"status"`
+	Floor     uint8  `json:"floor"`
+	SoundFlag uint8  `json:"soundFlag"`
+	ModelType uint8  `json:"modelType"`
+	EmSetFlag int8   `json:"emSetFlag"`
+	X         int16  `json:"x"`
+	Y         int16  `json:"y"`
+	Z         int16  `json:"z"`
+	DirY      uint16 `json:"dirY"`
+	Motion    uint16 `json:"motion"`
+	CtrFlag   uint16 `json:"ctrFlag"`
+	// Init is true if the enemy is spawned by the init script when the room is entered,
+	// and false if it is spawned later by a sub script
+	Init bool `json:"init"`
+	// Conditions of the enclosing blocks. The enemy is always spawned if it is empty.
+	Condition string `json:"condition"`
+	Source    Source `json:"source"`
+}
+
+// EnemyOpcodes are the opcodes that place the enemies found by FindEnemies
+var EnemyOpcodes = []byte{fileio.OP_SCE_EM_SET}
+
+// FindEnemies returns every enemy spawned by the scripts of a room
+func FindEnemies(room Room) []Enemy {
+	enemies := make([]Enemy, 0)
+	for _, line := range scriptInstructions(room) {
+		instr, ok := line.instruction.Decoded.(fileio.ScriptInstrSceEmSet)
+		if !ok {
+			continue
+		}
+		enemies = append(enemies, Enemy{
+			Room:      room.Id,
+			Player:    room.Player,
+			Aot:       instr.Aot,
+			Id:        instr.Id,
+			Type:      instr.Type,
+			Status:    instr.Status,
+			Floor:     instr.Floor,
+			SoundFlag: instr.SoundFlag,
+			ModelType: instr.ModelType,
+			EmSetFlag: instr.EmSetFlag,
+			X:         instr.X,
+			Y:         instr.Y,
+			Z:         instr.Z,
+			DirY:      instr.DirY,
+			Motion:    instr.Motion,
+			CtrFlag:   instr.CtrFlag,
+			Init:      line.source.ScriptFile == "init.scd",
+			Condition: line.condition,
+			Source:    line.source,
+		})
+	}
+	return enemies
+}
+
+// FindAllEnemies returns the enemies of every room
+func FindAllEnemies(rooms []Room) []Enemy {
+	enemies := make([]Enemy, 0)
+	for _, room := range rooms {
+		enemies = append(enemies, FindEnemies(room)...)
+	}
+	return enemies
+}
+
+// WriteEnemiesCSV writes one row for every enemy with a header row
+func WriteEnemiesCSV(w io.Writer, enemies []Enemy) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"room", "player", "aot", "id", "type", "status", "floor", "sound_flag", "model_type",
+		"em_set_flag", "x", "y", "z", "dir_y", "motion", "ctr_flag", "init", "condition", "file", "script", "line"})
+	for _, enemy := range enemies {
+		writer.Write([]string{
+			enemy.Room,
+			formatInt(enemy.Player),
+			formatInt(enemy.Aot),
+			formatInt(enemy.Id),
+			formatInt(enemy.Type),
+			formatInt(enemy.Status),
+			formatInt(enemy.Floor),
+			formatInt(enemy.SoundFlag),
+			formatInt(enemy.ModelType),
+			formatInt(enemy.EmSetFlag),
+			formatInt(enemy.X),
+			formatInt(enemy.Y),
+			formatInt(enemy.Z),
+			formatInt(enemy.DirY),
+			formatInt(enemy.Motion),
+			formatInt(enemy.CtrFlag),
+			strconv.FormatBool(enemy.Init),
+			enemy.Condition,
+			enemy.Source.Filename,
+			enemy.Source.ScriptFile,
+			strconv.Itoa(enemy.Source.Line),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteEnemiesJSON writes the enemies as a JSON array
+func WriteEnemiesJSON(w io.Writer, enemies []Enemy) error {
+	return writeJSON(w, enemies)
+}
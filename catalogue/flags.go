@@ -0,0 +1,187 @@
+package catalogue
+
+// Cross-reference of the flags that are set and tested by every room
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Flag is a bit of a bit array used by SetBit and CheckBit
+type Flag struct {
+	BitArray  uint8 `json:"bitArray"`
+	BitNumber uint8 `json:"bitNumber"`
+}
+
+func (flag Flag) String() string {
+	return fmt.Sprintf("%d:%d", flag.BitArray, flag.BitNumber)
+}
+
+// ParseFlag reads a flag written as bit array:bit number, e.g. 1:5
+func ParseFlag(text string) (Flag, error) {
+	arrayText, numberText, found := strings.Cut(strings.TrimSpace(text), ":")
+	if !found {
+		return Flag{}, fmt.Errorf("expected bit array:bit number but got %q", text)
+	}
+	bitArray, err := strconv.ParseUint(strings.TrimSpace(arrayText), 0, 8)
+	if err != nil {
+		return Flag{}, fmt.Errorf("invalid bit array %q", arrayText)
+	}
+	bitNumber, err := strconv.ParseUint(strings.TrimSpace(numberText), 0, 8)
+	if err != nil {
+		return Flag{}, fmt.Errorf("invalid bit number %q", numberText)
+	}
+	return Flag{BitArray: uint8(bitArray), BitNumber: uint8(bitNumber)}, nil
+}
+
+// Ways a script line can use a flag
+const (
+	FLAG_SET   = "set"
+	FLAG_CLEAR = "clear"
+	FLAG_FLIP  = "flip"
+	FLAG_TEST  = "test"
+)
+
+// setBitAccess maps the operations of SetBit to the way the flag is used
+var setBitAccess = map[uint8]string{
+	fileio.SET_BIT_CLEAR: FLAG_CLEAR,
+	fileio.SET_BIT_SET:   FLAG_SET,
+	fileio.SET_BIT_FLIP:  FLAG_FLIP,
+}
+
+// FlagReference is a script line that changes or tests a flag
+type FlagReference struct {
+	Flag      Flag   `json:"flag"`
+	Access    string `json:"access"` // set, clear, flip or test
+	Value     uint8  `json:"value"`  // value the flag is compared with by a test
+	Room      string `json:"room"`   // room id, e.g. 100
+	Player    uint8  `json:"player"`
+	Condition string `json:"condition"` // conditions of the enclosing blocks
+	Source    Source `json:"source"`
+}
+
+// FlagIndex holds every reference of every flag in a set of rooms
+type FlagIndex struct {
+	References map[Flag][]FlagReference
+}
+
+// FindFlagReferences returns every SetBit and CheckBit of a room
+func FindFlagReferences(room Room) []FlagReference {
+	references := make([]FlagReference, 0)
+	for _, line := range scriptInstructions(room) {
+		reference := FlagReference{Room: room.Id, Player: room.Player, Condition: line.condition, Source: line.source}
+		switch instr := line.instruction.Decoded.(type) {
+		case fileio.ScriptInstrSetBit:
+			access, exists := setBitAccess[instr.Operation]
+			if !exists {
+				access = fmt.Sprintf("operation %d", instr.Operation)
+			}
+			reference.Flag = Flag{BitArray: instr.BitArray, BitNumber: instr.BitNumber}
+			reference.Access = access
+		case fileio.ScriptInstrCheckBitTest:
+			reference.Flag = Flag{BitArray: instr.BitArray, BitNumber: instr.BitNumber}
+			reference.Access = FLAG_TEST
+			reference.Value = instr.Value
+		default:
+			continue
+		}
+		references = append(references, reference)
+	}
+	return references
+}
+
+// NewFlagIndex indexes the flag references of every room
+func NewFlagIndex(rooms []Room) *FlagIndex {
+	index := &FlagIndex{References: make(map[Flag][]FlagReference)}
+	for _, room := range rooms {
+		for _, reference := range FindFlagReferences(room) {
+			index.References[reference.Flag] = append(index.References[reference.Flag], reference)
+		}
+	}
+	return index
+}
+
+// Flags returns every flag that is referenced, sorted by bit array and bit number
+func (index *FlagIndex) Flags() []Flag {
+	flags := make([]Flag, 0, len(index.References))
+	for flag := range index.References {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		if flags[i].BitArray != flags[j].BitArray {
+			return flags[i].BitArray < flags[j].BitArray
+		}
+		return flags[i].BitNumber < flags[j].BitNumber
+	})
+	return flags
+}
+
+// Find returns the references of a flag
+func (index *FlagIndex) Find(flag Flag) []FlagReference {
+	return index.References[flag]
+}
+
+// Rooms returns the rooms that use a flag in the way of the access, e.g. every room that sets it
+func (index *FlagIndex) Rooms(flag Flag, access string) []string {
+	rooms := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, reference := range index.References[flag] {
+		if reference.Access == access && !seen[reference.Room] {
+			seen[reference.Room] = true
+			rooms = append(rooms, reference.Room)
+		}
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// FormatReference describes where a flag is used, e.g. room 100 sub1.scd:12 set
+func FormatReference(reference FlagReference) string {
+	text := fmt.Sprintf("room %s %s:%d %s", reference.Room, reference.Source.ScriptFile, reference.Source.Line, reference.Access)
+	if reference.Access == FLAG_TEST {
+		text += fmt.Sprintf(" == %d", reference.Value)
+	}
+	if reference.Condition != "" {
+		text += " if " + reference.Condition
+	}
+	return text
+}
+
+// WriteCSV writes one row for every reference of every flag with a header row
+func (index *FlagIndex) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"bit_array", "bit_number", "access", "value", "room", "player", "condition", "file", "script", "line"})
+	for _, flag := range index.Flags() {
+		for _, reference := range index.References[flag] {
+			writer.Write([]string{
+				formatInt(flag.BitArray),
+				formatInt(flag.BitNumber),
+				reference.Access,
+				formatInt(reference.Value),
+				reference.Room,
+				formatInt(reference.Player),
+				reference.Condition,
+				reference.Source.Filename,
+				reference.Source.ScriptFile,
+				strconv.Itoa(reference.Source.Line),
+			})
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the references of every flag as a JSON array
+func (index *FlagIndex) WriteJSON(w io.Writer) error {
+	references := make([]FlagReference, 0)
+	for _, flag := range index.Flags() {
+		references = append(references, index.References[flag]...)
+	}
+	return writeJSON(w, references)
+}
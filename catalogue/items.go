@@ -0,0 +1,116 @@
+package catalogue
+
+// Catalogue of the item pickups of every room
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Item is an item pickup placed by ItemAotSet or ItemAotSet4p
+type Item struct {
+	Room            string `json:"room"` // room id, e.g. 100
+	Player          uint8  `json:"player"`
+	Aot             uint8  `json:"aot"`
+	ItemId          uint16 `json:"itemId"`
+	Amount          uint16 `json:"amount"`
+	ItemPickedIndex uint16 `json:"itemPickedIndex"` // flag that is set when the item was picked up
+	Floor           uint8  `json:"floor"`
+	// Bounding box of the pickup area
+	X     int16 `json:"x"`
+	Z     int16 `json:"z"`
+	Width int16 `json:"width"`
+	Depth int16 `json:"depth"`
+	// Conditions of the enclosing blocks, such as scenario and character flags.
+	// The item is always placed if it is empty.
+	Condition string `json:"condition"`
+	Source    Source `json:"source"`
+}
+
+// ItemOpcodes are the opcodes that place the items found by FindItems
+var ItemOpcodes = []byte{fileio.OP_ITEM_AOT_SET, fileio.OP_ITEM_AOT_SET_4P}
+
+// FindItems returns every item pickup placed by the scripts of a room
+func FindItems(room Room) []Item {
+	items := make([]Item, 0)
+	for _, line := range scriptInstructions(room) {
+		item := Item{Room: room.Id, Player: room.Player, Condition: line.condition, Source: line.source}
+		switch instr := line.instruction.Decoded.(type) {
+		case fileio.ScriptInstrItemAotSet:
+			item.Aot, item.ItemId, item.Amount, item.ItemPickedIndex = instr.Aot, instr.ItemId, instr.Amount, instr.ItemPickedIndex
+			item.Floor = instr.Floor
+			item.X, item.Z, item.Width, item.Depth = instr.X, instr.Z, instr.Width, instr.Depth
+		case fileio.ScriptInstrItemAotSet4p:
+			item.Aot, item.ItemId, item.Amount, item.ItemPickedIndex = instr.Aot, instr.ItemId, instr.Amount, instr.ItemPickedIndex
+			item.Floor = instr.Floor
+			item.X, item.Z, item.Width, item.Depth = boundingBox(instr.X1, instr.Z1, instr.X2, instr.Z2, instr.X3, instr.Z3, instr.X4, instr.Z4)
+		default:
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// FindAllItems returns the item pickups of every room
+func FindAllItems(rooms []Room) []Item {
+	items := make([]Item, 0)
+	for _, room := range rooms {
+		items = append(items, FindItems(room)...)
+	}
+	return items
+}
+
+// boundingBox returns the position and size of the rectangle around the corners of an AOT
+func boundingBox(x1, z1, x2, z2, x3, z3, x4, z4 int16) (int16, int16, int16, int16) {
+	minX, maxX := min(x1, x2, x3, x4), max(x1, x2, x3, x4)
+	minZ, maxZ := min(z1, z2, z3, z4), max(z1, z2, z3, z4)
+	return minX, minZ, maxX - minX, maxZ - minZ
+}
+
+// WriteItemsCSV writes one row for every item with a header row
+func WriteItemsCSV(w io.Writer, items []Item) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"room", "player", "aot", "item_id", "amount", "item_picked_index", "floor",
+		"x", "z", "width", "depth", "condition", "file", "script", "line"})
+	for _, item := range items {
+		writer.Write([]string{
+			item.Room,
+			formatInt(item.Player),
+			formatInt(item.Aot),
+			formatInt(item.ItemId),
+			formatInt(item.Amount),
+			formatInt(item.ItemPickedIndex),
+			formatInt(item.Floor),
+			formatInt(item.X),
+			formatInt(item.Z),
+			formatInt(item.Width),
+			formatInt(item.Depth),
+			item.Condition,
+			item.Source.Filename,
+			item.Source.ScriptFile,
+			strconv.Itoa(item.Source.Line),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteItemsJSON writes the items as a JSON array
+func WriteItemsJSON(w io.Writer, items []Item) error {
+	return writeJSON(w, items)
+}
+
+func formatInt[T int8 | uint8 | int16 | uint16](value T) string {
+	return strconv.Itoa(int(value))
+}
+
+func writeJSON(w io.Writer, value any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
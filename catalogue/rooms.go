@@ -0,0 +1,160 @@
+// Package catalogue collects the objects placed by the scripts of many rooms,
+// such as the doors between rooms, so that the whole game can be analysed at once.
+package catalogue
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/decompiler"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Room is a loaded RDT file
+type Room struct {
+	Filename string // path of the RDT file, or a fileio.DiscRoomPath for a room on a disc image
+	Id       string // number of the room, e.g. 100, or the file name if it does not follow the naming scheme
+	Player   uint8  // 0 for Leon, 1 for Claire
+	RDT      *fileio.RDTOutput
+}
+
+// LoadRooms loads a single RDT file or every RDT file in a directory and its subdirectories or on a disc image.
+// Files that cannot be parsed are skipped and returned as errors.
+func LoadRooms(path string) ([]Room, []error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	filenames := []string{path}
+	if !info.IsDir() && fileio.IsDiscImage(path) {
+		return loadDiscRooms(path)
+	}
+	if info.IsDir() {
+		filenames = filenames[:0]
+		err := filepath.WalkDir(path, func(filename string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(filename), ".rdt") {
+				filenames = append(filenames, filename)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, []error{err}
+		}
+		sort.Strings(filenames)
+	}
+
+	rooms := make([]Room, 0, len(filenames))
+	errs := make([]error, 0)
+	for _, filename := range filenames {
+		rdtOutput, err := fileio.LoadRDTFile(filename)
+		if err != nil {
+			errs = append(errs, roomError(filename, err))
+			continue
+		}
+		rooms = append(rooms, newRoom(filename, rdtOutput))
+	}
+	return rooms, errs
+}
+
+// loadDiscRooms loads every RDT file on a disc image
+func loadDiscRooms(image string) ([]Room, []error) {
+	disc, err := fileio.OpenDiscImage(image)
+	if err != nil {
+		return nil, []error{err}
+	}
+	defer disc.Close()
+	files, err := disc.RoomFiles()
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	rooms := make([]Room, 0, len(files))
+	errs := make([]error, 0)
+	for _, file := range files {
+		filename := fileio.DiscRoomPath(image, file.Path)
+		rdtOutput, err := disc.LoadRDT(file.Path)
+		if err != nil {
+			errs = append(errs, roomError(filename, err))
+			continue
+		}
+		rooms = append(rooms, newRoom(filename, rdtOutput))
+	}
+	return rooms, errs
+}
+
+// roomError adds the file name to an error, unless it is a problem that names its file already
+func roomError(filename string, err error) error {
+	var diagnostic *fileio.Diagnostic
+	if errors.As(err, &diagnostic) && diagnostic.File != "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", filename, err)
+}
+
+// newRoom names a loaded room after its file
+func newRoom(filename string, rdtOutput *fileio.RDTOutput) Room {
+	room := Room{Filename: filename, Id: filepath.Base(filename), RDT: rdtOutput}
+	if stage, roomNumber, player, ok := fileio.ParseRoomFileName(filename); ok {
+		room.Id = fileio.RoomId(stage, roomNumber)
+		room.Player = player
+	}
+	return room
+}
+
+// UnsupportedGames returns the games of the rooms that have none of the opcodes, e.g. ItemOpcodes.
+// The objects placed by these opcodes cannot be found in the rooms of such games.
+func UnsupportedGames(rooms []Room, opcodes []byte) []*fileio.Game {
+	games := make([]*fileio.Game, 0)
+	checked := make(map[*fileio.Game]bool)
+	for _, room := range rooms {
+		if room.RDT.Opcodes == nil || checked[room.RDT.Game] {
+			continue
+		}
+		checked[room.RDT.Game] = true
+		if !slices.ContainsFunc(opcodes, room.RDT.Opcodes.HasOpcode) {
+			games = append(games, room.RDT.Game)
+		}
+	}
+	return games
+}
+
+// Source is the script line that placed an object
+type Source struct {
+	Filename   string `json:"file"`   // path of the RDT file
+	ScriptFile string `json:"script"` // script file as in fileio.SplitRDTScripts, e.g. sub0.scd
+	Line       int    `json:"line"`   // line in the script file, starting at 1
+}
+
+// scriptInstruction is an instruction of a room together with the line it is on
+// and the conditions that have to be true for the instruction to run
+type scriptInstruction struct {
+	instruction decompiler.Instruction
+	source      Source
+	condition   string
+}
+
+// scriptInstructions returns every instruction of the init and room scripts of a room
+func scriptInstructions(room Room) []scriptInstruction {
+	walker := &conditionWalker{
+		room:                room,
+		fileProgramCounters: fileio.ScriptFileProgramCounters(room.RDT),
+		instructions:        make([]scriptInstruction, 0),
+	}
+	for _, function := range decompiler.Decompile(room.RDT.InitScriptData.ScriptData) {
+		walker.walk(function.Body, true, nil)
+	}
+	for _, function := range decompiler.Decompile(room.RDT.RoomScriptData.ScriptData) {
+		walker.walk(function.Body, false, nil)
+	}
+	return walker.instructions
+}
@@ -0,0 +1,48 @@
+package main
+
+// Subcommand that converts pseudocode back into script bytecode
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+func runAssemble(args []string) error {
+	flags := newFlagSet("assemble")
+	outPath := flags.String("o", "", "write the bytecode to this file instead of printing it in hexadecimal")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// A file name of - reads the pseudocode from standard input, e.g. from the dump command
+	var code []byte
+	var err error
+	if flags.Arg(0) == "-" {
+		code, err = io.ReadAll(os.Stdin)
+	} else {
+		code, err = os.ReadFile(flags.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	opcodes, err := fileio.SelectedOpcodes()
+	if err != nil {
+		return err
+	}
+	instructions, err := opcodes.AssembleScript(string(code))
+	if err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		fmt.Print(fileio.ConvertRawScriptInstructionsToString(instructions))
+		return nil
+	}
+	return os.WriteFile(*outPath, concatInstructions(instructions), 0644)
+}
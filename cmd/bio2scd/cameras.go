@@ -0,0 +1,29 @@
+package main
+
+// Subcommand that prints the cameras of a room
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+func runCameras(args []string) error {
+	flags := newFlagSet("cameras")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rdtOutput, err := loadRoom(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(fileio.ConvertCamerasToString(rdtOutput.CameraPositionData))
+	fmt.Println()
+	fmt.Print(fileio.ConvertCameraSwitchesToString(rdtOutput.CameraPositionData, rdtOutput.CameraSwitchData))
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+// Subcommand that lists the problems found while parsing rooms, and the warnings
+// that the other subcommands print for them
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/catalogue"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// loadRoom loads an RDT file and warns about the parts of the room that were skipped
+func loadRoom(filename string) (*fileio.RDTOutput, error) {
+	rdtOutput, err := fileio.LoadRDTFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	printDiagnostics(rdtOutput.Diagnostics)
+	return rdtOutput, nil
+}
+
+// printDiagnostics prints the problems of a room to stderr
+func printDiagnostics(diagnostics []*fileio.Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		fmt.Fprintln(os.Stderr, "bio2scd:", diagnostic.String())
+	}
+}
+
+func runCheck(args []string) error {
+	flags := newFlagSet("check")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// Rooms that cannot be loaded, e.g. at the first error in strict mode, are errors as well
+	rooms, errs := catalogue.LoadRooms(flags.Arg(0))
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, "bio2scd: error:", err)
+	}
+	for _, room := range rooms {
+		printDiagnostics(room.RDT.Diagnostics)
+	}
+
+	totalErrors, totalWarnings := len(errs), 0
+	roomsOfGame := make(map[*fileio.Game]int)
+	for _, room := range rooms {
+		errors, warnings := fileio.CountDiagnostics(room.RDT.Diagnostics)
+		totalErrors += errors
+		totalWarnings += warnings
+		roomsOfGame[room.RDT.Game]++
+	}
+	fmt.Printf("%d rooms, %d errors, %d warnings\n", len(rooms)+len(errs), totalErrors, totalWarnings)
+	for _, game := range fileio.Games {
+		if roomsOfGame[game] > 0 {
+			fmt.Printf("  %d rooms of %s\n", roomsOfGame[game], game.Name)
+		}
+	}
+	if totalErrors > 0 {
+		return fmt.Errorf("%d errors found", totalErrors)
+	}
+	return nil
+}
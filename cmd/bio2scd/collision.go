@@ -0,0 +1,47 @@
+package main
+
+// Subcommand that prints the collision boundaries of a room
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/roommap"
+)
+
+func runCollision(args []string) error {
+	flags := newFlagSet("collision")
+	svgPath := flags.String("svg", "", "write the floor plan to this SVG file instead of listing the boundaries")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rdtOutput, err := loadRoom(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *svgPath == "" {
+		fmt.Print(fileio.ConvertCollisionToString(rdtOutput.CollisionData))
+		return nil
+	}
+	return writeSVGFile(*svgPath, roommap.NewCollisionMap(rdtOutput.CollisionData))
+}
+
+// writeSVGFile exports the map as an SVG drawing
+func writeSVGFile(filename string, roomMap *roommap.Map) error {
+	return writeOutputFile(filename, func(w io.Writer) error {
+		return roommap.WriteSVG(w, roomMap)
+	})
+}
+
+// writePNGFile exports the map as a PNG image with the width in pixels
+func writePNGFile(filename string, roomMap *roommap.Map, width int) error {
+	return writeOutputFile(filename, func(w io.Writer) error {
+		return roommap.WritePNG(w, roomMap, width)
+	})
+}
@@ -0,0 +1,38 @@
+package main
+
+// Subcommand that lists the rooms on a PlayStation disc image
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+func runDisc(args []string) error {
+	flags := newFlagSet("disc")
+	all := flags.Bool("all", false, "list every file on the disc instead of the rooms")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	disc, err := fileio.OpenDiscImage(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer disc.Close()
+
+	files, err := disc.RoomFiles()
+	if *all {
+		files, err = disc.Files()
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		fmt.Printf("%s\t%d bytes\n", fileio.DiscRoomPath(flags.Arg(0), file.Path), file.Size)
+	}
+	return nil
+}
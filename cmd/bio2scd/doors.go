@@ -0,0 +1,85 @@
+package main
+
+// Subcommand that builds the graph of the doors between all rooms
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/catalogue"
+)
+
+// loadRooms loads every room of a directory and warns about the files that cannot be parsed
+// and the parts of the rooms that were skipped
+func loadRooms(path string) ([]catalogue.Room, error) {
+	rooms, errs := catalogue.LoadRooms(path)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, "bio2scd: warning:", err)
+	}
+	for _, room := range rooms {
+		printDiagnostics(room.RDT.Diagnostics)
+	}
+	if len(rooms) == 0 {
+		return nil, fmt.Errorf("no RDT files could be loaded from %s", path)
+	}
+	return rooms, nil
+}
+
+// warnUnsupported prints a warning for every game of the rooms that has none of the opcodes,
+// so that rooms without such objects can be told from rooms whose objects cannot be read
+func warnUnsupported(rooms []catalogue.Room, objects string, opcodes []byte) {
+	for _, game := range catalogue.UnsupportedGames(rooms, opcodes) {
+		fmt.Fprintf(os.Stderr, "bio2scd: warning: %s are not supported in rooms of %s\n", objects, game.Name)
+	}
+}
+
+// writeOutputFile creates a file and writes an export into it
+func writeOutputFile(filename string, write func(w io.Writer) error) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func runDoors(args []string) error {
+	flags := newFlagSet("doors")
+	dotPath := flags.String("dot", "", "write the room graph to this Graphviz DOT file")
+	jsonPath := flags.String("json", "", "write the rooms and doors to this JSON file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rooms, err := loadRooms(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	warnUnsupported(rooms, "doors", catalogue.DoorOpcodes)
+	graph := catalogue.NewDoorGraph(rooms)
+
+	if *dotPath == "" && *jsonPath == "" {
+		for _, door := range graph.Doors {
+			fmt.Printf("%s -> %s\tcamera %d\tkey %d\tdoor type %d\t%s:%d\n",
+				door.From, door.To, door.Camera, door.KeyId, door.DoorType, door.Source.ScriptFile, door.Source.Line)
+		}
+		return nil
+	}
+	if *dotPath != "" {
+		if err := writeOutputFile(*dotPath, graph.WriteDOT); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeOutputFile(*jsonPath, graph.WriteJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+// Subcommand that lists the enemy spawns of all rooms
+
+import (
+	"io"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/catalogue"
+)
+
+func runEnemies(args []string) error {
+	flags := newFlagSet("enemies")
+	csvPath := flags.String("csv", "", "write the enemies to this CSV file instead of printing them")
+	jsonPath := flags.String("json", "", "write the enemies to this JSON file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rooms, err := loadRooms(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	warnUnsupported(rooms, "enemies", catalogue.EnemyOpcodes)
+	enemies := catalogue.FindAllEnemies(rooms)
+
+	if *csvPath == "" && *jsonPath == "" {
+		return catalogue.WriteEnemiesCSV(os.Stdout, enemies)
+	}
+	if *csvPath != "" {
+		if err := writeOutputFile(*csvPath, func(w io.Writer) error { return catalogue.WriteEnemiesCSV(w, enemies) }); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeOutputFile(*jsonPath, func(w io.Writer) error { return catalogue.WriteEnemiesJSON(w, enemies) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+// Subcommand that finds the rooms that set and test each flag
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/catalogue"
+)
+
+func runFlags(args []string) error {
+	flags := newFlagSet("flags")
+	flagText := flags.String("flag", "", "only print the references of this flag, e.g. 1:5")
+	csvPath := flags.String("csv", "", "write every reference to this CSV file")
+	jsonPath := flags.String("json", "", "write every reference to this JSON file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rooms, err := loadRooms(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	index := catalogue.NewFlagIndex(rooms)
+
+	if *csvPath != "" || *jsonPath != "" {
+		if *csvPath != "" {
+			if err := writeOutputFile(*csvPath, index.WriteCSV); err != nil {
+				return err
+			}
+		}
+		if *jsonPath != "" {
+			if err := writeOutputFile(*jsonPath, index.WriteJSON); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if *flagText != "" {
+		flag, err := catalogue.ParseFlag(*flagText)
+		if err != nil {
+			return err
+		}
+		references := index.Find(flag)
+		if len(references) == 0 {
+			return fmt.Errorf("flag %s is not used by any room", flag)
+		}
+		for _, reference := range references {
+			fmt.Println(catalogue.FormatReference(reference))
+		}
+		return nil
+	}
+
+	// Summary of the rooms that change and test every flag
+	for _, flag := range index.Flags() {
+		fmt.Printf("flag %s\n", flag)
+		for _, access := range []string{catalogue.FLAG_SET, catalogue.FLAG_CLEAR, catalogue.FLAG_FLIP, catalogue.FLAG_TEST} {
+			if rooms := index.Rooms(flag, access); len(rooms) > 0 {
+				fmt.Printf("\t%-5s %v\n", access, rooms)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+// Subcommand that lists the item pickups of all rooms
+
+import (
+	"io"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/catalogue"
+)
+
+func runItems(args []string) error {
+	flags := newFlagSet("items")
+	csvPath := flags.String("csv", "", "write the items to this CSV file instead of printing them")
+	jsonPath := flags.String("json", "", "write the items to this JSON file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rooms, err := loadRooms(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	warnUnsupported(rooms, "items", catalogue.ItemOpcodes)
+	items := catalogue.FindAllItems(rooms)
+
+	if *csvPath == "" && *jsonPath == "" {
+		return catalogue.WriteItemsCSV(os.Stdout, items)
+	}
+	if *csvPath != "" {
+		if err := writeOutputFile(*csvPath, func(w io.Writer) error { return catalogue.WriteItemsCSV(w, items) }); err != nil {
+			return err
+		}
+	}
+	if *jsonPath != "" {
+		if err := writeOutputFile(*jsonPath, func(w io.Writer) error { return catalogue.WriteItemsJSON(w, items) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+// Command bio2scd is the headless command-line interface of the script viewer.
+// It loads RDT files with the fileio package and prints the scripts without
+// opening any window, so it can be used on build servers and in shell pipelines.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// command is a subcommand of the command-line interface
+type command struct {
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+var commands map[string]command
+
+func init() {
+	// Registered in init because the subcommands refer back to the table for their usage messages
+	commands = map[string]command{
+		"list": {
+			usage:       "list <file.rdt>",
+			description: "List the script files in a room",
+			run:         runList,
+		},
+		"check": {
+			usage:       "check <dir|file.rdt>",
+			description: "List the problems found while parsing the rooms and fail if any room has errors",
+			run:         runCheck,
+		},
+		"enemies": {
+			usage:       "enemies [-csv out.csv] [-json out.json] <dir|file.rdt>",
+			description: "List the enemies spawned in every room with the conditions of the blocks around them",
+			run:         runEnemies,
+		},
+		"flags": {
+			usage:       "flags [-flag 1:5] [-csv out.csv] [-json out.json] <dir|file.rdt>",
+			description: "List the rooms that set, clear, flip or test each flag, or every reference of one flag",
+			run:         runFlags,
+		},
+		"hex": {
+			usage:       "hex [-script name] <file.rdt>",
+			description: "Print the bytecode of the scripts in hexadecimal",
+			run:         runHex,
+		},
+		"dump": {
+			usage:       "dump [-script name] [-hex] <file.rdt>",
+			description: "Print the scripts as pseudocode",
+			run:         runDump,
+		},
+		"decompile": {
+			usage:       "decompile [-script name] <file.rdt>",
+			description: "Print the scripts as structured pseudocode with indented blocks",
+			run:         runDecompile,
+		},
+		"assemble": {
+			usage:       "assemble [-o out.scd] <script.txt|->",
+			description: "Convert pseudocode in the format of the dump command back into bytecode",
+			run:         runAssemble,
+		},
+		"disc": {
+			usage:       "disc [-all] <image.iso|image.bin|image.cue>",
+			description: "List the rooms on a disc image with the names that the other commands accept for them",
+			run:         runDisc,
+		},
+		"doors": {
+			usage:       "doors [-dot out.dot] [-json out.json] <dir|file.rdt>",
+			description: "List the doors of every room in a directory or export the room graph",
+			run:         runDoors,
+		},
+		"items": {
+			usage:       "items [-csv out.csv] [-json out.json] <dir|file.rdt>",
+			description: "List the item pickups of every room with the conditions of the blocks around them",
+			run:         runItems,
+		},
+		"messages": {
+			usage:       "messages [-lang 1|2] <file.rdt>",
+			description: "Print the message text shown in the room",
+			run:         runMessages,
+		},
+		"cameras": {
+			usage:       "cameras <file.rdt>",
+			description: "List the camera positions of a room and the camera switches of each camera",
+			run:         runCameras,
+		},
+		"collision": {
+			usage:       "collision [-svg out.svg] <file.rdt>",
+			description: "List the collision boundaries of a room or draw them as a floor plan",
+			run:         runCollision,
+		},
+		"patch": {
+			usage:       "patch -o out.rdt -script sub0.scd=sub0.txt <file.rdt>",
+			description: "Write a copy of a room with scripts replaced by assembled pseudocode",
+			run:         runPatch,
+		},
+		"map": {
+			usage:       "map [-svg out.svg] [-png out.png] [-width px] <file.rdt>",
+			description: "List the AOTs, doors, items, enemies and objects placed by the scripts or draw them on a map",
+			run:         runMap,
+		},
+		"symbols": {
+			usage:       "symbols [-json] [-o out.toml]",
+			description: "Print the symbol table used to name items, enemies, flags, music and rooms",
+			run:         runSymbols,
+		},
+		"opcodes": {
+			usage:       "opcodes [-json] [-o out.toml]",
+			description: "Print the name, size and fields of every opcode in the format of an opcode spec file",
+			run:         runOpcodes,
+		},
+		"run": {
+			usage:       "run [-ticks n] [-trace] [-set \"bit 1:5\"] <file.rdt>",
+			description: "Simulate the script threads of a room and print the engine events they trigger",
+			run:         runRun,
+		},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: bio2scd [-game auto|id] [-strict] [-symbols file.toml|file.json] [-opcodes spec.toml|spec.json] <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "The -game option reads the rooms as rooms of this game instead of detecting the game of every room.")
+	gameIds := make([]string, len(fileio.Games))
+	for i, game := range fileio.Games {
+		gameIds[i] = game.Id
+	}
+	fmt.Fprintf(os.Stderr, "The games are %s.\n", strings.Join(gameIds, ", "))
+	fmt.Fprintln(os.Stderr, "The -strict option stops at the first error in a room instead of skipping the broken part.")
+	fmt.Fprintln(os.Stderr, "The -symbols option adds names from a symbol file to the built-in names (can be repeated).")
+	fmt.Fprintln(os.Stderr, "The -opcodes option replaces the built-in definitions of the opcodes in a spec file (can be repeated).")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-40s %s\n", commands[name].usage, commands[name].description)
+	}
+}
+
+// newFlagSet creates the flag set of a subcommand with a usage message matching the command table
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: bio2scd %s\n", commands[name].usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// symbolFiles loads the symbol files given with the global -symbols option
+type symbolFiles struct{}
+
+func (symbolFiles) String() string {
+	return ""
+}
+
+func (symbolFiles) Set(filename string) error {
+	return fileio.LoadSymbolFile(filename)
+}
+
+// opcodeSpecFiles loads the opcode spec files given with the global -opcodes option
+type opcodeSpecFiles struct{}
+
+func (opcodeSpecFiles) String() string {
+	return ""
+}
+
+func (opcodeSpecFiles) Set(filename string) error {
+	return fileio.LoadOpcodeSpecFile(filename)
+}
+
+// gameFlag selects the game given with the global -game option
+type gameFlag struct{}
+
+func (gameFlag) String() string {
+	return "auto"
+}
+
+func (gameFlag) Set(id string) error {
+	if id == "auto" {
+		fileio.SelectedGame = nil
+		return nil
+	}
+	game, err := fileio.FindGame(id)
+	if err != nil {
+		return err
+	}
+	// The opcodes of the game are checked before any room is read
+	if _, err := game.Opcodes(); err != nil {
+		return err
+	}
+	fileio.SelectedGame = game
+	return nil
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Var(gameFlag{}, "game", "")
+	flag.BoolVar(&fileio.StrictParsing, "strict", false, "")
+	flag.Var(symbolFiles{}, "symbols", "")
+	flag.Var(opcodeSpecFiles{}, "opcodes", "")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, exists := commands[flag.Arg(0)]
+	if !exists {
+		fmt.Fprintf(os.Stderr, "bio2scd: unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "bio2scd:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+// Subcommand that prints the message text of a room
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+func runMessages(args []string) error {
+	flags := newFlagSet("messages")
+	lang := flags.Int("lang", 0, "only print the messages of this language block (1 or 2)")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *lang < 0 || *lang > 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rdtOutput, err := loadRoom(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	languages := []*fileio.MSGOutput{rdtOutput.Lang1MessageData, rdtOutput.Lang2MessageData}
+	for i, msgOutput := range languages {
+		if *lang != 0 && *lang != i+1 {
+			continue
+		}
+
+		fmt.Printf("// Lang%d%s\n", i+1, fileio.MessageLanguageNote(msgOutput))
+		for id, message := range msgOutput.Messages {
+			// Keep one message per line so the output can be used with grep
+			fmt.Printf("%d\t%s\n", id, strings.ReplaceAll(message, "\n", "\\n"))
+		}
+		fmt.Println()
+	}
+	return nil
+}
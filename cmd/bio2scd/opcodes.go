@@ -0,0 +1,34 @@
+package main
+
+// Subcommand that prints the opcode definitions, e.g. as a starting point for an opcode spec file
+
+import (
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+func runOpcodes(args []string) error {
+	flags := newFlagSet("opcodes")
+	asJSON := flags.Bool("json", false, "print the definitions as JSON instead of TOML")
+	outPath := flags.String("o", "", "write the definitions to this file instead of stdout")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	opcodes, err := fileio.SelectedOpcodes()
+	if err != nil {
+		return err
+	}
+	spec := opcodes.Definitions()
+	write := spec.WriteTOML
+	if *asJSON {
+		write = spec.WriteJSON
+	}
+	if *outPath != "" {
+		return writeOutputFile(*outPath, write)
+	}
+	return write(os.Stdout)
+}
@@ -0,0 +1,79 @@
+package main
+
+// Subcommand that writes a room file with replaced scripts
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// scriptReplacements collects the -script name=file.txt flags of the patch command
+type scriptReplacements map[string]string
+
+func (replacements scriptReplacements) String() string {
+	return fmt.Sprint(map[string]string(replacements))
+}
+
+func (replacements scriptReplacements) Set(value string) error {
+	name, filename, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("expected name=file.txt but got %q", value)
+	}
+	replacements[name] = filename
+	return nil
+}
+
+func runPatch(args []string) error {
+	flags := newFlagSet("patch")
+	outPath := flags.String("o", "", "write the patched room to this file")
+	replacements := scriptReplacements{}
+	flags.Var(replacements, "script", "replace a script with pseudocode from a file, e.g. sub0.scd=sub0.txt (can be repeated)")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *outPath == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// The scripts are assembled with the opcodes of the game of the room
+	room, err := loadRoom(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	patch := fileio.RDTPatch{RoomFunctions: make(map[int][]byte)}
+	for name, filename := range replacements {
+		code, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		instructions, err := room.Opcodes.AssembleScript(string(code))
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		if name == "init.scd" {
+			patch.InitFunctions = room.Opcodes.SplitInitFunctions(instructions)
+			continue
+		}
+		functionNum, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "sub"), ".scd"))
+		if err != nil || !strings.HasPrefix(name, "sub") || !strings.HasSuffix(name, ".scd") {
+			return fmt.Errorf("script %s not found, the scripts are init.scd and sub0.scd, sub1.scd, ...", name)
+		}
+		patch.RoomFunctions[functionNum] = concatInstructions(instructions)
+	}
+
+	return fileio.PatchRDTFile(flags.Arg(0), *outPath, patch)
+}
+
+// concatInstructions joins the bytecode of the instructions of a function
+func concatInstructions(instructions [][]byte) []byte {
+	function := make([]byte, 0)
+	for _, lineBytes := range instructions {
+		function = append(function, lineBytes...)
+	}
+	return function
+}
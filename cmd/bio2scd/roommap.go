@@ -0,0 +1,47 @@
+package main
+
+// Subcommand that draws the objects placed by the room scripts on a map
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/roommap"
+)
+
+func runMap(args []string) error {
+	flags := newFlagSet("map")
+	svgPath := flags.String("svg", "", "write the map to this SVG file")
+	pngPath := flags.String("png", "", "write the map to this PNG file")
+	width := flags.Int("width", 1024, "width of the PNG image in pixels")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rdtOutput, err := loadRoom(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	roomMap := roommap.NewRoomMap(rdtOutput)
+
+	if *svgPath == "" && *pngPath == "" {
+		for _, shape := range roomMap.ScriptObjects() {
+			center := shape.Center()
+			fmt.Printf("%-8s [%6.0f, %6.0f]  %s\n", roommap.LayerName[shape.Layer], center.X, center.Z, shape.Title)
+		}
+		return nil
+	}
+	if *svgPath != "" {
+		if err := writeSVGFile(*svgPath, roomMap); err != nil {
+			return err
+		}
+	}
+	if *pngPath != "" {
+		if err := writePNGFile(*pngPath, roomMap, *width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
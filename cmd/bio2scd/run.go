@@ -0,0 +1,78 @@
+package main
+
+// Subcommand that simulates the script threads of a room
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/interpreter"
+)
+
+// stateAssignments collects the -set flags of the run command
+type stateAssignments struct {
+	state *interpreter.State
+}
+
+func (assignments stateAssignments) String() string {
+	return ""
+}
+
+func (assignments stateAssignments) Set(value string) error {
+	return assignments.state.Apply(value)
+}
+
+func runRun(args []string) error {
+	flags := newFlagSet("run")
+	ticks := flags.Int("ticks", 300, "number of ticks to simulate")
+	trace := flags.Bool("trace", false, "print every instruction that is executed")
+	state := interpreter.NewState()
+	flags.Var(stateAssignments{state}, "set", "change the state before the room starts, e.g. \"bit 1:5\", \"var 3=2\" or \"item 23\" (can be repeated)")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rdtOutput, err := loadRoom(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	interp, err := interpreter.NewRoom(rdtOutput, state)
+	if err != nil {
+		return err
+	}
+
+	printed := 0
+	for interp.Tick < *ticks {
+		if *trace {
+			if thread, instruction, ok := interp.Current(); ok && instruction != nil {
+				fmt.Printf("tick %d thread %d %04x: %s%s\n", interp.Tick, thread.Id, instruction.Offset(),
+					instruction.Name(), fileio.GetOpcodeSignature(instruction))
+			}
+		}
+		running, err := interp.Step()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "bio2scd:", err)
+		}
+		if !*trace {
+			for ; printed < len(interp.Events); printed++ {
+				fmt.Println(interp.Events[printed])
+			}
+		}
+		if !running {
+			break
+		}
+	}
+
+	running := 0
+	for _, thread := range interp.Threads {
+		if !thread.Finished {
+			running++
+		}
+	}
+	fmt.Printf("\n// state after %d ticks, %d threads running\n", interp.Tick, running)
+	fmt.Print(interp.State)
+	return nil
+}
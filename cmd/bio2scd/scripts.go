@@ -0,0 +1,145 @@
+package main
+
+// Subcommands that print the scripts of a single room
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/decompiler"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// loadScriptFiles loads an RDT file and returns the room, its script files and their names in sorted order
+func loadScriptFiles(filename string) (*fileio.RDTOutput, map[string][][]byte, []string, error) {
+	rdtOutput, err := loadRoom(filename)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	scriptFiles := fileio.SplitRDTScripts(rdtOutput)
+	return rdtOutput, scriptFiles, fileio.SortedScriptFilenames(scriptFiles), nil
+}
+
+// selectScriptFiles returns the file names to print, either all of them or only the requested one
+func selectScriptFiles(scriptFiles map[string][][]byte, filenames []string, script string) ([]string, error) {
+	if script == "" {
+		return filenames, nil
+	}
+	if _, exists := scriptFiles[script]; !exists {
+		return nil, fmt.Errorf("script %s not found, available scripts: %s", script, strings.Join(filenames, ", "))
+	}
+	return []string{script}, nil
+}
+
+func runList(args []string) error {
+	flags := newFlagSet("list")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	_, scriptFiles, filenames, err := loadScriptFiles(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range filenames {
+		fmt.Printf("%s\t%d instructions\n", filename, len(scriptFiles[filename]))
+	}
+	return nil
+}
+
+func runHex(args []string) error {
+	flags := newFlagSet("hex")
+	script := flags.String("script", "", "only print this script file, e.g. sub0.scd")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	_, scriptFiles, filenames, err := loadScriptFiles(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	selected, err := selectScriptFiles(scriptFiles, filenames, *script)
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range selected {
+		fmt.Printf("// %s\n", filename)
+		fmt.Print(fileio.ConvertRawScriptInstructionsToString(scriptFiles[filename]))
+		fmt.Println()
+	}
+	return nil
+}
+
+func runDump(args []string) error {
+	flags := newFlagSet("dump")
+	script := flags.String("script", "", "only print this script file, e.g. sub0.scd")
+	showHex := flags.Bool("hex", false, "print the bytecode of each instruction next to the pseudocode")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rdtOutput, scriptFiles, filenames, err := loadScriptFiles(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	selected, err := selectScriptFiles(scriptFiles, filenames, *script)
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range selected {
+		fmt.Printf("// %s\n", filename)
+		instructions := scriptFiles[filename]
+		if !*showHex {
+			fmt.Print(fileio.ConvertScriptInstructionsToCode(instructions, rdtOutput))
+			fmt.Println()
+			continue
+		}
+
+		// Print hex and pseudocode of each instruction on the same line
+		for _, lineBytes := range instructions {
+			hex := strings.TrimSuffix(fileio.ConvertRawScriptInstructionsToString([][]byte{lineBytes}), "\n")
+			code := strings.TrimSuffix(fileio.ConvertScriptInstructionsToCode([][]byte{lineBytes}, rdtOutput), "\n")
+			fmt.Printf("%-48s %s\n", hex, code)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runDecompile(args []string) error {
+	flags := newFlagSet("decompile")
+	script := flags.String("script", "", "only print this script file, e.g. sub0.scd")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	rdtOutput, scriptFiles, filenames, err := loadScriptFiles(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	selected, err := selectScriptFiles(scriptFiles, filenames, *script)
+	if err != nil {
+		return err
+	}
+
+	decompiledFiles := decompiler.DecompileRDT(rdtOutput)
+	for _, filename := range selected {
+		fmt.Printf("// %s\n", filename)
+		fmt.Print(decompiledFiles[filename])
+		fmt.Println()
+	}
+	return nil
+}
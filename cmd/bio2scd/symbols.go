@@ -0,0 +1,29 @@
+package main
+
+// Subcommand that prints the symbol table, e.g. as a starting point for a symbol file
+
+import (
+	"os"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+func runSymbols(args []string) error {
+	flags := newFlagSet("symbols")
+	asJSON := flags.Bool("json", false, "print the table as JSON instead of TOML")
+	outPath := flags.String("o", "", "write the table to this file instead of stdout")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	write := fileio.Symbols.WriteTOML
+	if *asJSON {
+		write = fileio.Symbols.WriteJSON
+	}
+	if *outPath != "" {
+		return writeOutputFile(*outPath, write)
+	}
+	return write(os.Stdout)
+}
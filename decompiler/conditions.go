@@ -0,0 +1,70 @@
+package decompiler
+
+// Folds the condition opcodes of a block into a boolean expression
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// compareOperators maps the comparison operations of Compare and MemberCmp to operators
+var compareOperators = map[uint8]string{
+	fileio.CMP_EQ:  "==",
+	fileio.CMP_GT:  ">",
+	fileio.CMP_GE:  ">=",
+	fileio.CMP_LT:  "<",
+	fileio.CMP_LE:  "<=",
+	fileio.CMP_NE:  "!=",
+	fileio.CMP_AND: "&",
+}
+
+// FormatCondition folds the condition opcodes of a block into a single expression.
+// The checks are joined with && because the engine only enters the block if every check succeeds.
+func FormatCondition(conditions []Instruction, rdtOutput *fileio.RDTOutput) string {
+	if len(conditions) == 0 {
+		return "true"
+	}
+	parts := make([]string, 0, len(conditions))
+	for _, condition := range conditions {
+		parts = append(parts, FormatConditionExpression(condition, rdtOutput))
+	}
+	return strings.Join(parts, " && ")
+}
+
+// FormatConditionExpression converts a single condition opcode into an expression,
+// e.g. CheckBit(BitArray=4, BitNumber=12, Value=1) becomes flag[4][12] == 1.
+// Checks without a known expression are shown as a function call.
+func FormatConditionExpression(condition Instruction, rdtOutput *fileio.RDTOutput) string {
+	switch condition.Opcode() {
+	case fileio.OP_CHECK:
+		instr := decode[fileio.ScriptInstrCheckBitTest](condition)
+		return fmt.Sprintf("flag[%d][%d] == %d", instr.BitArray, instr.BitNumber, instr.Value)
+	case fileio.OP_COMPARE:
+		instr := decode[fileio.ScriptInstrCompare](condition)
+		if expression, ok := formatComparison(fmt.Sprintf("var[%d]", instr.VarId), instr.Operation, instr.Value); ok {
+			return expression
+		}
+	case fileio.OP_MEMBER_CMP:
+		instr := decode[fileio.ScriptInstrMemberCompare](condition)
+		if expression, ok := formatComparison(fmt.Sprintf("member[%d]", instr.MemberIndex), instr.CompareOperation, instr.Value); ok {
+			return expression
+		}
+	case fileio.OP_KEEP_ITEM_CK:
+		instr := decode[fileio.ScriptInstrKeepItemCk](condition)
+		return fmt.Sprintf("KeepItem(%d)", instr.ItemId)
+	}
+	return strings.TrimSuffix(FormatInstruction(condition, rdtOutput), ";")
+}
+
+func formatComparison(operand string, operation uint8, value int16) (string, bool) {
+	operator, exists := compareOperators[operation]
+	if !exists {
+		return "", false
+	}
+	if operation == fileio.CMP_AND {
+		return fmt.Sprintf("(%s & %d) != 0", operand, value), true
+	}
+	return fmt.Sprintf("%s %s %d", operand, operator, value), true
+}
@@ -0,0 +1,395 @@
+// Package decompiler reconstructs the structured control flow of script functions.
+//
+// The bytecode stores blocks as a start instruction with a block length
+// (IfStart, ElseStart, ForStart, WhileStart, DoStart, Switch and Case) followed
+// by the instructions of the block. The decompiler turns these into a tree of
+// nested if/else, for, while, do and switch/case nodes that can be printed as
+// indented pseudocode by the GUI or by any exporter.
+package decompiler
+
+import (
+	"sort"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Instruction is a single script command located at a program counter
+type Instruction struct {
+	ProgramCounter int
+	Bytes          []byte
+	Decoded        any // struct of the opcode from fileio.Instruction
+
+	source fileio.Instruction // decoded instruction with the canonical opcode from the game of the room
+}
+
+// Opcode returns the RE2 opcode with the same meaning as the opcode of the instruction
+func (instr Instruction) Opcode() byte {
+	return instr.source.Opcode()
+}
+
+// End returns the program counter of the next instruction
+func (instr Instruction) End() int {
+	return instr.ProgramCounter + len(instr.Bytes)
+}
+
+// NodeKind is the type of a node in the decompiled tree
+type NodeKind int
+
+const (
+	NodeInstruction NodeKind = iota // a plain instruction
+	NodeIf                          // if (Conditions) { Body } else { Else }
+	NodeFor                         // for (Count) { Body }
+	NodeWhile                       // while (Conditions) { Body }
+	NodeDo                          // do { Body } while (Conditions)
+	NodeSwitch                      // switch (VarId) { Cases }
+)
+
+// Node is a statement of a decompiled function
+type Node struct {
+	Kind        NodeKind
+	Instruction Instruction   // the instruction itself, or the instruction starting the block
+	Conditions  []Instruction // condition opcodes of if, while and do blocks
+	Body        []*Node
+	Else        []*Node // only set for if blocks with an else branch
+	HasElse     bool
+	Cases       []*Case // only set for switch blocks
+}
+
+// Case is a case or default label of a switch block
+type Case struct {
+	Instruction Instruction // Case or Default instruction, empty for instructions before the first label
+	Default     bool
+	Body        []*Node
+}
+
+// Function is a decompiled script function
+type Function struct {
+	Index               int
+	StartProgramCounter int
+	Body                []*Node
+}
+
+// conditionOpcodes are the opcodes that are evaluated as the condition of a block
+var conditionOpcodes = map[byte]bool{
+	fileio.OP_CHECK:        true,
+	fileio.OP_COMPARE:      true,
+	fileio.OP_MEMBER_CMP:   true,
+	fileio.OP_KEEP_ITEM_CK: true,
+	fileio.OP_SCE_TRG_CK:   true,
+	fileio.OP_DIR_CK:       true,
+}
+
+// IsConditionOpcode returns true if the opcode checks a condition of an if, while or do block
+func IsConditionOpcode(opcode byte) bool {
+	return conditionOpcodes[opcode]
+}
+
+// Decompile reconstructs the blocks of every function in the script
+func Decompile(script fileio.ScriptFunction) []*Function {
+	functions := make([]*Function, 0, len(script.StartProgramCounter))
+	for index, instructions := range SplitFunctions(script) {
+		d := &decompiler{instructions: instructions}
+		functions = append(functions, &Function{
+			Index:               index,
+			StartProgramCounter: script.StartProgramCounter[index],
+			Body:                d.parseBlock(0, len(instructions)),
+		})
+	}
+	return functions
+}
+
+// SplitFunctions returns the instructions of each function in program counter order.
+// The Sleeping entry that the parser stores inside every Sleep instruction is not part
+// of the decoded program, because it is part of the Sleep command and not a separate instruction.
+func SplitFunctions(script fileio.ScriptFunction) [][]Instruction {
+	starts := append([]int(nil), script.StartProgramCounter...)
+	sort.Ints(starts)
+
+	functions := make([][]Instruction, len(starts))
+	functionNum := -1
+	nextStart := 0
+	for _, decoded := range script.Program {
+		for nextStart < len(starts) && decoded.Offset() >= starts[nextStart] {
+			functionNum++
+			nextStart++
+		}
+		if functionNum < 0 {
+			continue
+		}
+
+		instr := Instruction{ProgramCounter: decoded.Offset(), Bytes: decoded.Bytes(), Decoded: decoded.Decoded(), source: decoded}
+		functions[functionNum] = append(functions[functionNum], instr)
+	}
+	return functions
+}
+
+// decode returns the struct of the instruction, or the zero value if the instruction has a different opcode
+func decode[T any](instr Instruction) T {
+	decoded, _ := instr.Decoded.(T)
+	return decoded
+}
+
+type decompiler struct {
+	instructions []Instruction
+}
+
+// indexAt returns the index of the instruction starting at the program counter.
+// The program counter directly after the last instruction maps to the number of instructions.
+func (d *decompiler) indexAt(programCounter int, start int, end int) (int, bool) {
+	for i := start; i < end; i++ {
+		if d.instructions[i].ProgramCounter == programCounter {
+			return i, true
+		}
+		if d.instructions[i].ProgramCounter > programCounter {
+			return 0, false
+		}
+	}
+	if end > 0 && end <= len(d.instructions) && d.instructions[end-1].End() == programCounter {
+		return end, true
+	}
+	return 0, false
+}
+
+// parseBlock decompiles the instructions in the index range [start, end)
+func (d *decompiler) parseBlock(start int, end int) []*Node {
+	nodes := make([]*Node, 0)
+	for i := start; i < end; {
+		node, next := d.parseNode(i, end)
+		nodes = append(nodes, node)
+		i = next
+	}
+	return nodes
+}
+
+// parseNode decompiles the statement at index i and returns the index of the next statement.
+// Blocks whose length does not match the instruction boundaries are shown as plain instructions.
+func (d *decompiler) parseNode(i int, end int) (*Node, int) {
+	instr := d.instructions[i]
+	switch instr.Opcode() {
+	case fileio.OP_IF_START:
+		if node, next, ok := d.parseIf(i, end); ok {
+			return node, next
+		}
+	case fileio.OP_FOR:
+		if node, next, ok := d.parseLoop(i, end, NodeFor, fileio.OP_FOR_END); ok {
+			return node, next
+		}
+	case fileio.OP_WHILE_START:
+		if node, next, ok := d.parseLoop(i, end, NodeWhile, fileio.OP_WHILE_END); ok {
+			return node, next
+		}
+	case fileio.OP_DO_START:
+		if node, next, ok := d.parseLoop(i, end, NodeDo, fileio.OP_DO_END); ok {
+			return node, next
+		}
+	case fileio.OP_SWITCH:
+		if node, next, ok := d.parseSwitch(i, end); ok {
+			return node, next
+		}
+	}
+	return &Node{Kind: NodeInstruction, Instruction: instr}, i + 1
+}
+
+// blockEnd returns the index after a block that starts at index i.
+// The block length counts the bytes following the block start instruction.
+func (d *decompiler) blockEnd(i int, end int, blockLength int) (int, bool) {
+	instr := d.instructions[i]
+	blockEnd, ok := d.indexAt(instr.End()+blockLength, i+1, end)
+	if !ok || blockEnd <= i {
+		return 0, false
+	}
+	return blockEnd, true
+}
+
+// conditionsEnd returns the index after the condition opcodes that start at index i
+func (d *decompiler) conditionsEnd(i int, end int) int {
+	for i < end && IsConditionOpcode(d.instructions[i].Opcode()) {
+		i++
+	}
+	return i
+}
+
+// trimEnd removes the instruction closing a block from the end of the range if it is present
+func (d *decompiler) trimEnd(start int, end int, opcode byte) int {
+	if end > start && d.instructions[end-1].Opcode() == opcode {
+		return end - 1
+	}
+	return end
+}
+
+// skipEnd skips the instruction closing a block if it directly follows the block
+func (d *decompiler) skipEnd(next int, end int, opcode byte) int {
+	if next < end && d.instructions[next].Opcode() == opcode {
+		return next + 1
+	}
+	return next
+}
+
+func (d *decompiler) parseIf(i int, end int) (*Node, int, bool) {
+	ifInstr := decode[fileio.ScriptInstrIfElseStart](d.instructions[i])
+	ifEnd, ok := d.blockEnd(i, end, int(ifInstr.BlockLength))
+	if !ok {
+		return nil, 0, false
+	}
+
+	node := &Node{Kind: NodeIf, Instruction: d.instructions[i]}
+	bodyStart := d.conditionsEnd(i+1, ifEnd)
+	node.Conditions = d.instructions[i+1 : bodyStart]
+
+	bodyEnd := ifEnd
+	blockEnd := ifEnd
+	// The if block ends with the else instruction when there is an else branch
+	if ifEnd > bodyStart && d.instructions[ifEnd-1].Opcode() == fileio.OP_ELSE_START {
+		elseInstr := decode[fileio.ScriptInstrElseStart](d.instructions[ifEnd-1])
+		elseEnd, ok := d.indexAt(d.instructions[ifEnd-1].ProgramCounter+int(elseInstr.BlockLength), ifEnd, end)
+		if ok {
+			bodyEnd = ifEnd - 1
+			node.HasElse = true
+			blockEnd = elseEnd
+		}
+	}
+
+	// EndIf is either the last instruction of the block or directly follows it
+	next := blockEnd
+	lastStart := bodyStart
+	if node.HasElse {
+		lastStart = ifEnd
+	}
+	lastEnd := d.trimEnd(lastStart, blockEnd, fileio.OP_END_IF)
+	if lastEnd == blockEnd {
+		next = d.skipEnd(blockEnd, end, fileio.OP_END_IF)
+	}
+
+	if node.HasElse {
+		node.Body = d.parseBlock(bodyStart, bodyEnd)
+		node.Else = d.parseBlock(ifEnd, lastEnd)
+	} else {
+		node.Body = d.parseBlock(bodyStart, lastEnd)
+	}
+	return node, next, true
+}
+
+func (d *decompiler) parseLoop(i int, end int, kind NodeKind, endOpcode byte) (*Node, int, bool) {
+	instr := d.instructions[i]
+	var blockLength int
+	switch decoded := instr.Decoded.(type) {
+	case fileio.ScriptInstrForStart:
+		blockLength = int(decoded.BlockLength)
+	case fileio.ScriptInstrWhileStart:
+		blockLength = int(decoded.BlockLength)
+	case fileio.ScriptInstrDoStart:
+		blockLength = int(decoded.BlockLength)
+	default:
+		return nil, 0, false
+	}
+
+	loopEnd, ok := d.blockEnd(i, end, blockLength)
+	if !ok {
+		loopEnd, ok = d.findLoopEnd(i, end, endOpcode)
+		if !ok {
+			return nil, 0, false
+		}
+	}
+
+	node := &Node{Kind: kind, Instruction: instr}
+	bodyStart := i + 1
+	bodyEnd := d.trimEnd(bodyStart, loopEnd, endOpcode)
+	next := loopEnd
+	if bodyEnd == loopEnd {
+		next = d.skipEnd(next, end, endOpcode)
+	}
+
+	switch kind {
+	case NodeWhile:
+		// The conditions are checked at the start of each iteration
+		bodyStart = d.conditionsEnd(bodyStart, bodyEnd)
+		node.Conditions = d.instructions[i+1 : bodyStart]
+	case NodeDo:
+		// The conditions are checked at the end of each iteration
+		condStart := bodyEnd
+		for condStart > bodyStart && IsConditionOpcode(d.instructions[condStart-1].Opcode()) {
+			condStart--
+		}
+		node.Conditions = d.instructions[condStart:bodyEnd]
+		bodyEnd = condStart
+	}
+
+	node.Body = d.parseBlock(bodyStart, bodyEnd)
+	return node, next, true
+}
+
+// findLoopEnd looks for the matching loop end instruction when the block length is inconsistent
+func (d *decompiler) findLoopEnd(i int, end int, endOpcode byte) (int, bool) {
+	startOpcode := d.instructions[i].Opcode()
+	depth := 0
+	for j := i + 1; j < end; j++ {
+		switch d.instructions[j].Opcode() {
+		case startOpcode:
+			depth++
+		case endOpcode:
+			if depth == 0 {
+				return j + 1, true
+			}
+			depth--
+		}
+	}
+	return 0, false
+}
+
+func (d *decompiler) parseSwitch(i int, end int) (*Node, int, bool) {
+	switchInstr := decode[fileio.ScriptInstrSwitch](d.instructions[i])
+	switchEnd, ok := d.blockEnd(i, end, int(switchInstr.BlockLength))
+	if !ok {
+		return nil, 0, false
+	}
+
+	node := &Node{Kind: NodeSwitch, Instruction: d.instructions[i]}
+	bodyEnd := d.trimEnd(i+1, switchEnd, fileio.OP_END_SWITCH)
+	next := switchEnd
+	if bodyEnd == switchEnd {
+		next = d.skipEnd(next, end, fileio.OP_END_SWITCH)
+	}
+
+	j := i + 1
+	for j < bodyEnd {
+		instr := d.instructions[j]
+		switch instr.Opcode() {
+		case fileio.OP_CASE:
+			caseInstr := decode[fileio.ScriptInstrSwitchCase](instr)
+			caseEnd, ok := d.blockEnd(j, bodyEnd, int(caseInstr.BlockLength))
+			if !ok {
+				caseEnd = d.nextCase(j+1, bodyEnd)
+			}
+			node.Cases = append(node.Cases, &Case{Instruction: instr, Body: d.parseBlock(j+1, caseEnd)})
+			j = caseEnd
+		case fileio.OP_DEFAULT:
+			caseEnd := d.nextCase(j+1, bodyEnd)
+			node.Cases = append(node.Cases, &Case{Instruction: instr, Default: true, Body: d.parseBlock(j+1, caseEnd)})
+			j = caseEnd
+		default:
+			// Instructions before the first case are never executed, but keep them visible
+			caseEnd := d.nextCase(j, bodyEnd)
+			node.Cases = append(node.Cases, &Case{Body: d.parseBlock(j, caseEnd)})
+			j = caseEnd
+		}
+	}
+	return node, next, true
+}
+
+// nextCase returns the index of the next case or default label of the switch block
+func (d *decompiler) nextCase(i int, end int) int {
+	depth := 0
+	for ; i < end; i++ {
+		switch d.instructions[i].Opcode() {
+		case fileio.OP_SWITCH:
+			depth++
+		case fileio.OP_END_SWITCH:
+			depth--
+		case fileio.OP_CASE, fileio.OP_DEFAULT:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return end
+}
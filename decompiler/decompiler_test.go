@@ -0,0 +1,197 @@
+package decompiler
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Helpers that build the bytecode of blocks with the block lengths the parser expects.
+// IfStart, Case and the loops count their length from the end of the start instruction,
+// ElseStart counts it from its own offset.
+
+func code(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+func withLength(opcode byte, first byte, length int, rest ...byte) []byte {
+	return append([]byte{opcode, first, byte(length), byte(length >> 8)}, rest...)
+}
+
+func gosub(event byte) []byte {
+	return []byte{fileio.OP_GOSUB, event}
+}
+
+func checkBit(bitArray byte, bitNumber byte, value byte) []byte {
+	return []byte{fileio.OP_CHECK, bitArray, bitNumber, value}
+}
+
+func ifBlock(conditions []byte, body []byte) []byte {
+	block := code(conditions, body, []byte{fileio.OP_END_IF})
+	return code(withLength(fileio.OP_IF_START, 0, len(block)), block)
+}
+
+func ifElseBlock(conditions []byte, body []byte, elseBody []byte) []byte {
+	elseStart := withLength(fileio.OP_ELSE_START, 0, 4+len(elseBody))
+	block := code(conditions, body, elseStart)
+	return code(withLength(fileio.OP_IF_START, 0, len(block)), block, elseBody, []byte{fileio.OP_END_IF})
+}
+
+func forBlock(count byte, body []byte) []byte {
+	block := code(body, []byte{fileio.OP_FOR_END, 0})
+	return code(withLength(fileio.OP_FOR, 0, len(block), count, 0), block)
+}
+
+func whileBlock(conditions []byte, body []byte) []byte {
+	block := code(conditions, body, []byte{fileio.OP_WHILE_END, 0})
+	return code(withLength(fileio.OP_WHILE_START, 0, len(block)), block)
+}
+
+func doBlock(body []byte, conditions []byte) []byte {
+	block := code(body, conditions, []byte{fileio.OP_DO_END, 0})
+	return code(withLength(fileio.OP_DO_START, 0, len(block)), block)
+}
+
+func switchBlock(varId byte, cases []byte) []byte {
+	block := code(cases, []byte{fileio.OP_END_SWITCH, 0})
+	return code(withLength(fileio.OP_SWITCH, varId, len(block)), block)
+}
+
+func caseBlock(value byte, body []byte) []byte {
+	return code(withLength(fileio.OP_CASE, 0, len(body), value, 0), body)
+}
+
+func defaultBlock(body []byte) []byte {
+	return code([]byte{fileio.OP_DEFAULT, 0}, body)
+}
+
+var breakInstr = []byte{fileio.OP_BREAK, 0}
+
+// decompileFunction parses the bytecode of a single RE2 function and prints it as pseudocode
+func decompileFunction(t *testing.T, function []byte) string {
+	t.Helper()
+	opcodes, err := fileio.GameRE2.Opcodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := fileio.BuildSCD([][]byte{function})
+	output, err := fileio.LoadRDT_SCDStream(bytes.NewReader(script), int64(len(script)), opcodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Diagnostics) != 0 {
+		t.Fatalf("bytecode has problems: %v", output.Diagnostics)
+	}
+	functions := Decompile(output.ScriptData)
+	if len(functions) != 1 {
+		t.Fatalf("got %d functions, want 1", len(functions))
+	}
+	return FormatFunction(functions[0], nil)
+}
+
+func TestDecompile(t *testing.T) {
+	evtEnd := []byte{fileio.OP_EVT_END}
+	tests := []struct {
+		name     string
+		function []byte
+		want     string
+	}{
+		{
+			name:     "if",
+			function: code(ifBlock(checkBit(1, 5, 1), gosub(2)), evtEnd),
+			want: "if (flag[1][5] == 1) {\n" +
+				"    Gosub(Event=2);\n" +
+				"}\n" +
+				"EvtEnd();\n",
+		},
+		{
+			name:     "if else",
+			function: code(ifElseBlock(code(checkBit(1, 5, 1), checkBit(1, 6, 0)), gosub(2), gosub(3)), gosub(4), evtEnd),
+			want: "if (flag[1][5] == 1 && flag[1][6] == 0) {\n" +
+				"    Gosub(Event=2);\n" +
+				"} else {\n" +
+				"    Gosub(Event=3);\n" +
+				"}\n" +
+				"Gosub(Event=4);\n" +
+				"EvtEnd();\n",
+		},
+		{
+			name:     "nested if else",
+			function: code(ifElseBlock(checkBit(1, 5, 1), ifElseBlock(checkBit(1, 6, 1), gosub(2), gosub(3)), gosub(4)), evtEnd),
+			want: "if (flag[1][5] == 1) {\n" +
+				"    if (flag[1][6] == 1) {\n" +
+				"        Gosub(Event=2);\n" +
+				"    } else {\n" +
+				"        Gosub(Event=3);\n" +
+				"    }\n" +
+				"} else {\n" +
+				"    Gosub(Event=4);\n" +
+				"}\n" +
+				"EvtEnd();\n",
+		},
+		{
+			name: "switch with fall-through",
+			function: code(switchBlock(7, code(
+				caseBlock(1, nil),
+				caseBlock(2, code(gosub(2), breakInstr)),
+				caseBlock(3, gosub(3)),
+				defaultBlock(gosub(4)),
+			)), evtEnd),
+			want: "switch (VarId=7) {\n" +
+				"    case 1:\n" +
+				"    case 2:\n" +
+				"        Gosub(Event=2);\n" +
+				"        break;\n" +
+				"    case 3:\n" +
+				"        Gosub(Event=3);\n" +
+				"    default:\n" +
+				"        Gosub(Event=4);\n" +
+				"}\n" +
+				"EvtEnd();\n",
+		},
+		{
+			name:     "nested for loops",
+			function: code(forBlock(3, code(gosub(1), forBlock(2, gosub(2)))), evtEnd),
+			want: "for (Count=3) {\n" +
+				"    Gosub(Event=1);\n" +
+				"    for (Count=2) {\n" +
+				"        Gosub(Event=2);\n" +
+				"    }\n" +
+				"}\n" +
+				"EvtEnd();\n",
+		},
+		{
+			name:     "while in do",
+			function: code(doBlock(whileBlock(checkBit(1, 5, 0), gosub(2)), checkBit(1, 6, 1)), evtEnd),
+			want: "do {\n" +
+				"    while (flag[1][5] == 0) {\n" +
+				"        Gosub(Event=2);\n" +
+				"    }\n" +
+				"} while (flag[1][6] == 1);\n" +
+				"EvtEnd();\n",
+		},
+		{
+			name:     "if in loop",
+			function: code(whileBlock(checkBit(1, 5, 0), ifBlock(checkBit(1, 6, 1), breakInstr)), evtEnd),
+			want: "while (flag[1][5] == 0) {\n" +
+				"    if (flag[1][6] == 1) {\n" +
+				"        break;\n" +
+				"    }\n" +
+				"}\n" +
+				"EvtEnd();\n",
+		},
+		{
+			name:     "inconsistent block length",
+			function: code(withLength(fileio.OP_IF_START, 0, 100), gosub(2), evtEnd),
+			want: "IfStart(Dummy=0, BlockLength=100);\n" +
+				"Gosub(Event=2);\n" +
+				"EvtEnd();\n",
+		},
+	}
+	for _, test := range tests {
+		if got := decompileFunction(t, test.function); got != test.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, got, test.want)
+		}
+	}
+}
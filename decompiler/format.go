@@ -0,0 +1,108 @@
+package decompiler
+
+// Prints the decompiled tree as indented pseudocode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+const indentUnit = "    "
+
+// DecompileRDT decompiles the scripts of a room and returns the pseudocode of each
+// script file, keyed by the same file names as fileio.SplitRDTScripts
+func DecompileRDT(rdtOutput *fileio.RDTOutput) map[string]string {
+	scriptFiles := make(map[string]string)
+	for _, function := range Decompile(rdtOutput.RoomScriptData.ScriptData) {
+		scriptFiles[fmt.Sprintf("sub%d.scd", function.Index)] = FormatFunction(function, rdtOutput)
+	}
+
+	// All init functions are shown in one file
+	var builder strings.Builder
+	for _, function := range Decompile(rdtOutput.InitScriptData.ScriptData) {
+		builder.WriteString(FormatFunction(function, rdtOutput))
+	}
+	scriptFiles["init.scd"] = builder.String()
+	return scriptFiles
+}
+
+// FormatFunction prints a decompiled function as indented pseudocode.
+// The room is used to show the data that parameters refer to and can be nil.
+func FormatFunction(function *Function, rdtOutput *fileio.RDTOutput) string {
+	p := &printer{rdtOutput: rdtOutput}
+	p.writeNodes(function.Body, 0)
+	return p.builder.String()
+}
+
+// FormatInstruction prints a single instruction the same way as the flat pseudocode view
+func FormatInstruction(instr Instruction, rdtOutput *fileio.RDTOutput) string {
+	return instr.source.Name() + fileio.GetRoomOpcodeSignature(instr.source, rdtOutput)
+}
+
+type printer struct {
+	builder   strings.Builder
+	rdtOutput *fileio.RDTOutput
+}
+
+func (p *printer) writeLine(depth int, line string) {
+	p.builder.WriteString(strings.Repeat(indentUnit, depth))
+	p.builder.WriteString(line)
+	p.builder.WriteString("\n")
+}
+
+func (p *printer) writeNodes(nodes []*Node, depth int) {
+	for _, node := range nodes {
+		p.writeNode(node, depth)
+	}
+}
+
+func (p *printer) writeNode(node *Node, depth int) {
+	switch node.Kind {
+	case NodeIf:
+		p.writeLine(depth, fmt.Sprintf("if (%s) {", FormatCondition(node.Conditions, p.rdtOutput)))
+		p.writeNodes(node.Body, depth+1)
+		if node.HasElse {
+			p.writeLine(depth, "} else {")
+			p.writeNodes(node.Else, depth+1)
+		}
+		p.writeLine(depth, "}")
+	case NodeFor:
+		forInstr := decode[fileio.ScriptInstrForStart](node.Instruction)
+		p.writeLine(depth, fmt.Sprintf("for (Count=%d) {", forInstr.Count))
+		p.writeNodes(node.Body, depth+1)
+		p.writeLine(depth, "}")
+	case NodeWhile:
+		p.writeLine(depth, fmt.Sprintf("while (%s) {", FormatCondition(node.Conditions, p.rdtOutput)))
+		p.writeNodes(node.Body, depth+1)
+		p.writeLine(depth, "}")
+	case NodeDo:
+		p.writeLine(depth, "do {")
+		p.writeNodes(node.Body, depth+1)
+		p.writeLine(depth, fmt.Sprintf("} while (%s);", FormatCondition(node.Conditions, p.rdtOutput)))
+	case NodeSwitch:
+		switchInstr := decode[fileio.ScriptInstrSwitch](node.Instruction)
+		p.writeLine(depth, fmt.Sprintf("switch (VarId=%d) {", switchInstr.VarId))
+		for _, switchCase := range node.Cases {
+			switch {
+			case switchCase.Instruction.Bytes == nil:
+				p.writeNodes(switchCase.Body, depth+1)
+				continue
+			case switchCase.Default:
+				p.writeLine(depth+1, "default:")
+			default:
+				caseInstr := decode[fileio.ScriptInstrSwitchCase](switchCase.Instruction)
+				p.writeLine(depth+1, fmt.Sprintf("case %d:", caseInstr.Value))
+			}
+			p.writeNodes(switchCase.Body, depth+2)
+		}
+		p.writeLine(depth, "}")
+	default:
+		if node.Instruction.Opcode() == fileio.OP_BREAK {
+			p.writeLine(depth, "break;")
+			return
+		}
+		p.writeLine(depth, FormatInstruction(node.Instruction, p.rdtOutput))
+	}
+}
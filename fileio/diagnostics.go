@@ -0,0 +1,114 @@
+package fileio
+
+// Problems found while parsing a room, such as unknown opcodes in modded or beta rooms
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StrictParsing makes LoadRDT fail at the first error. Otherwise the parser skips the
+// broken part of a room, e.g. the rest of a script function, and records the problem
+// in the diagnostics of the room.
+var StrictParsing = false
+
+// Sections of a room in the diagnostics
+const (
+	SectionInitScript      = "init script"
+	SectionRoomScript      = "room script"
+	SectionLang1           = "Lang1 messages"
+	SectionLang2           = "Lang2 messages"
+	SectionCameraPositions = "camera positions"
+	SectionCameraSwitches  = "camera switches"
+	SectionCollision       = "collision data"
+)
+
+// NoFunction is the function index of diagnostics outside of a script function
+const NoFunction = -1
+
+// Severity tells whether a problem stops the parser in strict mode
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (severity Severity) String() string {
+	if severity == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic is a problem found while parsing a room. It is returned as the error of
+// LoadRDT in strict mode and collected in RDTOutput.Diagnostics otherwise.
+type Diagnostic struct {
+	Severity Severity
+	File     string // RDT file, empty if the room was not loaded from a file
+	Section  string // one of the Section constants
+	Function int    // index of the script function, or NoFunction
+	Offset   int64  // byte offset in the file, or in the section before LoadRDT adds the section offset
+	Message  string
+}
+
+func (diagnostic *Diagnostic) Error() string {
+	var builder strings.Builder
+	if diagnostic.File != "" {
+		builder.WriteString(diagnostic.File + ": ")
+	}
+	if diagnostic.Section != "" {
+		builder.WriteString(diagnostic.Section + " ")
+	}
+	if diagnostic.Function != NoFunction {
+		fmt.Fprintf(&builder, "function %d ", diagnostic.Function)
+	}
+	fmt.Fprintf(&builder, "at offset 0x%x: %s", diagnostic.Offset, diagnostic.Message)
+	return builder.String()
+}
+
+// String prefixes the problem with its severity
+func (diagnostic *Diagnostic) String() string {
+	return diagnostic.Severity.String() + ": " + diagnostic.Error()
+}
+
+// FirstError returns the first diagnostic with SeverityError, or nil if there is none
+func FirstError(diagnostics []*Diagnostic) error {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return diagnostic
+		}
+	}
+	return nil
+}
+
+// CountDiagnostics returns the number of errors and warnings
+func CountDiagnostics(diagnostics []*Diagnostic) (int, int) {
+	errors, warnings := 0, 0
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// reportProblem records a problem. Errors are returned in strict mode so that the parser stops.
+func reportProblem(diagnostics *[]*Diagnostic, diagnostic *Diagnostic) error {
+	*diagnostics = append(*diagnostics, diagnostic)
+	if StrictParsing && diagnostic.Severity == SeverityError {
+		return diagnostic
+	}
+	return nil
+}
+
+// relocateDiagnostics sets the section of the problems found by a section parser and
+// turns their offsets within the section into offsets within the file
+func relocateDiagnostics(diagnostics []*Diagnostic, section string, sectionOffset int64) {
+	for _, diagnostic := range diagnostics {
+		diagnostic.Section = section
+		diagnostic.Offset += sectionOffset
+	}
+}
@@ -0,0 +1,330 @@
+package fileio
+
+// .iso, .bin, .cue - PlayStation disc images with an ISO9660 file system
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	discSectorDataSize   = 2048 // user data of a sector, which is the block size of ISO9660
+	discVolumeDescriptor = 16   // sector of the primary volume descriptor
+	discRootRecordOffset = 156  // offset of the root directory record in the volume descriptor
+	discDirectoryFlag    = 0x02
+)
+
+// discSectorFormats are the layouts of the sectors in an image: plain 2048 byte sectors in
+// .iso files, raw 2352 byte sectors of Mode 2 Form 1 (PlayStation) or Mode 1 in .bin files,
+// and 2336 byte Mode 2 sectors without sync and header
+var discSectorFormats = []struct {
+	sectorSize int64
+	dataOffset int64 // offset of the user data in a sector
+}{
+	{2048, 0},
+	{2352, 24},
+	{2352, 16},
+	{2336, 8},
+}
+
+// DiscImage is an opened disc image
+type DiscImage struct {
+	Filename   string // .iso, .bin or .cue file that was opened
+	file       *os.File
+	sectors    int64 // number of sectors in the image file
+	sectorSize int64
+	dataOffset int64
+	root       DiscFile
+}
+
+// DiscFile is a file or directory on a disc image
+type DiscFile struct {
+	Path  string // path with slashes and without the version, e.g. PL0/RDT/ROOM1000.RDT
+	Size  int64
+	IsDir bool
+
+	sector uint32 // first sector of the data
+}
+
+// IsDiscImage tells whether a file name has the extension of a disc image
+func IsDiscImage(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".iso", ".bin", ".cue", ".img":
+		return true
+	}
+	return false
+}
+
+// DiscRoomPath returns the name of a room on a disc image as accepted by LoadRDTFile,
+// e.g. re2.cue:PL0/RDT/ROOM1000.RDT
+func DiscRoomPath(image string, path string) string {
+	return image + ":" + path
+}
+
+// SplitDiscRoomPath splits the name of a room on a disc image into the image and the path on the disc
+func SplitDiscRoomPath(name string) (string, string, bool) {
+	separator := strings.LastIndex(name, ":")
+	if separator < 0 || !IsDiscImage(name[:separator]) {
+		return "", "", false
+	}
+	return name[:separator], name[separator+1:], true
+}
+
+// OpenDiscImage opens a disc image. The sector format is detected from the volume descriptor,
+// and a .cue sheet is read to find the .bin file of its first track.
+func OpenDiscImage(filename string) (*DiscImage, error) {
+	imageFilename := filename
+	if strings.EqualFold(filepath.Ext(filename), ".cue") {
+		binFilename, err := readCueSheet(filename)
+		if err != nil {
+			return nil, err
+		}
+		imageFilename = binFilename
+	}
+
+	file, err := os.Open(imageFilename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open disc image %s: %w", imageFilename, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open disc image %s: %w", imageFilename, err)
+	}
+	disc := &DiscImage{Filename: filename, file: file}
+	for _, format := range discSectorFormats {
+		disc.sectorSize, disc.dataOffset = format.sectorSize, format.dataOffset
+		disc.sectors = info.Size() / format.sectorSize
+		descriptor := make([]byte, discSectorDataSize)
+		if _, err := disc.readSectors(discVolumeDescriptor, descriptor); err != nil {
+			continue
+		}
+		if descriptor[0] != 1 || string(descriptor[1:6]) != "CD001" {
+			continue
+		}
+		disc.root = parseDirectoryRecord(descriptor[discRootRecordOffset:])
+		disc.root.Path = ""
+		return disc, nil
+	}
+	file.Close()
+	return nil, fmt.Errorf("%s is not an ISO9660 disc image", filename)
+}
+
+// readCueSheet returns the .bin file of the first track of a cue sheet
+func readCueSheet(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to open cue sheet %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		command, rest, _ := strings.Cut(line, " ")
+		if !strings.EqualFold(command, "FILE") {
+			continue
+		}
+		// FILE "name.bin" BINARY, the name is quoted if it contains spaces
+		binFilename := rest
+		if strings.HasPrefix(rest, "\"") {
+			binFilename, _, _ = strings.Cut(rest[1:], "\"")
+		} else {
+			binFilename, _, _ = strings.Cut(rest, " ")
+		}
+		if !filepath.IsAbs(binFilename) {
+			binFilename = filepath.Join(filepath.Dir(filename), binFilename)
+		}
+		return binFilename, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("cue sheet %s has no FILE", filename)
+}
+
+// Close closes the image file
+func (disc *DiscImage) Close() error {
+	return disc.file.Close()
+}
+
+// readSectors reads the user data of the sectors starting at the sector into the buffer
+func (disc *DiscImage) readSectors(sector int64, buffer []byte) (int, error) {
+	return discFileReader{disc: disc, sector: sector, size: int64(len(buffer))}.ReadAt(buffer, 0)
+}
+
+// Files returns every file on the disc sorted by path
+func (disc *DiscImage) Files() ([]DiscFile, error) {
+	files := make([]DiscFile, 0)
+	visited := make(map[uint32]bool)
+	var walk func(directory DiscFile) error
+	walk = func(directory DiscFile) error {
+		if visited[directory.sector] {
+			return nil
+		}
+		visited[directory.sector] = true
+
+		entries, err := disc.readDirectory(directory)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir {
+				if err := walk(entry); err != nil {
+					return err
+				}
+				continue
+			}
+			files = append(files, entry)
+		}
+		return nil
+	}
+	if err := walk(disc.root); err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// RoomFiles returns the RDT files on the disc, e.g. in PL0/RDT or in the STAGE directories
+func (disc *DiscImage) RoomFiles() ([]DiscFile, error) {
+	files, err := disc.Files()
+	if err != nil {
+		return nil, err
+	}
+	rooms := make([]DiscFile, 0)
+	for _, file := range files {
+		if strings.EqualFold(filepath.Ext(file.Path), ".rdt") {
+			rooms = append(rooms, file)
+		}
+	}
+	return rooms, nil
+}
+
+// Open returns a reader for the file with the path. Paths are not case sensitive.
+func (disc *DiscImage) Open(path string) (*io.SectionReader, error) {
+	directory := disc.root
+	parts := strings.Split(strings.Trim(filepath.ToSlash(path), "/"), "/")
+	for i, part := range parts {
+		entries, err := disc.readDirectory(directory)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, entry := range entries {
+			if strings.EqualFold(filepath.Base(entry.Path), part) && entry.IsDir == (i < len(parts)-1) {
+				directory, found = entry, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: file %s not found", disc.Filename, path)
+		}
+	}
+	reader := discFileReader{disc: disc, sector: int64(directory.sector), size: directory.Size}
+	return io.NewSectionReader(reader, 0, directory.Size), nil
+}
+
+// LoadRDT loads the room with the path on the disc
+func (disc *DiscImage) LoadRDT(path string) (*RDTOutput, error) {
+	reader, err := disc.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	output, err := LoadRDT(reader, reader.Size())
+	return output, setDiagnosticsFile(output, err, DiscRoomPath(disc.Filename, path))
+}
+
+// readDirectory returns the entries of a directory without . and ..
+func (disc *DiscImage) readDirectory(directory DiscFile) ([]DiscFile, error) {
+	// The size comes from the image, so it is checked before the directory is allocated
+	if directory.Size > (disc.sectors-int64(directory.sector))*discSectorDataSize {
+		return nil, fmt.Errorf("%s: directory /%s has %d bytes, which is more than the rest of the image", disc.Filename, directory.Path, directory.Size)
+	}
+	data := make([]byte, directory.Size)
+	if _, err := disc.readSectors(int64(directory.sector), data); err != nil {
+		return nil, fmt.Errorf("%s: failed to read directory %s: %w", disc.Filename, directory.Path, err)
+	}
+
+	entries := make([]DiscFile, 0)
+	for position := 0; position < len(data); {
+		recordLength := int(data[position])
+		// Records do not cross sectors, the rest of a sector is padded with zeros
+		if recordLength == 0 {
+			position = (position/discSectorDataSize + 1) * discSectorDataSize
+			continue
+		}
+		if position+recordLength > len(data) || recordLength < 34 {
+			return nil, fmt.Errorf("%s: directory %s has an invalid record at 0x%x", disc.Filename, directory.Path, position)
+		}
+		entry := parseDirectoryRecord(data[position : position+recordLength])
+		position += recordLength
+		if entry.Path == "\x00" || entry.Path == "\x01" {
+			continue
+		}
+		if directory.Path != "" {
+			entry.Path = directory.Path + "/" + entry.Path
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseDirectoryRecord reads the location, size, flags and name of a directory record.
+// The name of the root directory and of . and .. is a single byte.
+func parseDirectoryRecord(record []byte) DiscFile {
+	nameLength := int(record[32])
+	name := string(bytes.TrimRight(record[33:min(33+nameLength, len(record))], "\x00"))
+	if nameLength == 1 && record[33] <= 1 {
+		name = string(record[33:34])
+	}
+	// File names end with the version, e.g. ROOM1000.RDT;1, and names without extension with a dot
+	name, _, _ = strings.Cut(name, ";")
+	if len(name) > 1 {
+		name = strings.TrimSuffix(name, ".")
+	}
+	return DiscFile{
+		Path:   name,
+		Size:   int64(binary.LittleEndian.Uint32(record[10:14])),
+		IsDir:  record[25]&discDirectoryFlag != 0,
+		sector: binary.LittleEndian.Uint32(record[2:6]),
+	}
+}
+
+// discFileReader reads the user data of consecutive sectors, skipping the sync, header and
+// error correction bytes of raw sectors
+type discFileReader struct {
+	disc   *DiscImage
+	sector int64 // first sector
+	size   int64
+}
+
+func (reader discFileReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	n := 0
+	for n < len(p) && off < reader.size {
+		sector := reader.sector + off/discSectorDataSize
+		inSector := off % discSectorDataSize
+		chunk := min(int64(len(p)-n), discSectorDataSize-inSector, reader.size-off)
+		position := sector*reader.disc.sectorSize + reader.disc.dataOffset + inSector
+		read, err := reader.disc.file.ReadAt(p[n:n+int(chunk)], position)
+		n += read
+		off += int64(read)
+		if err != nil {
+			return n, err
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
@@ -0,0 +1,177 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Sectors of the test image
+const (
+	testRootSector = 18
+	testPL0Sector  = 19
+	testRDTSector  = 20
+	testRoomSector = 21 // the room spans two sectors to test reading across sectors
+	testTextSector = 23
+	testSectors    = 24
+)
+
+var (
+	testRoomData = bytes.Repeat([]byte("ROOM"), 700)
+	testTextData = []byte("hello")
+)
+
+// directoryRecord builds an ISO9660 directory record
+func directoryRecord(name string, sector uint32, size uint32, isDir bool) []byte {
+	record := make([]byte, 33+len(name)+(1-len(name)%2))
+	record[0] = byte(len(record))
+	binary.LittleEndian.PutUint32(record[2:6], sector)
+	binary.LittleEndian.PutUint32(record[10:14], size)
+	if isDir {
+		record[25] = discDirectoryFlag
+	}
+	record[32] = byte(len(name))
+	copy(record[33:], name)
+	return record
+}
+
+func directory(sector uint32, parent uint32, entries ...[]byte) []byte {
+	data := bytes.Join(append([][]byte{
+		directoryRecord("\x00", sector, discSectorDataSize, true),
+		directoryRecord("\x01", parent, discSectorDataSize, true),
+	}, entries...), nil)
+	return append(data, make([]byte, discSectorDataSize-len(data))...)
+}
+
+// buildDiscImage returns the user data of a small disc with PL0/RDT/ROOM1000.RDT and README.TXT.
+// rootSize is the size of the root directory in its record in the volume descriptor.
+func buildDiscImage(rootSize uint32) []byte {
+	data := make([]byte, testSectors*discSectorDataSize)
+	sector := func(number int) []byte {
+		return data[number*discSectorDataSize : (number+1)*discSectorDataSize]
+	}
+
+	descriptor := sector(discVolumeDescriptor)
+	descriptor[0] = 1
+	copy(descriptor[1:6], "CD001")
+	copy(descriptor[discRootRecordOffset:], directoryRecord("\x00", testRootSector, rootSize, true))
+
+	copy(sector(testRootSector), directory(testRootSector, testRootSector,
+		directoryRecord("PL0", testPL0Sector, discSectorDataSize, true),
+		directoryRecord("README.TXT;1", testTextSector, uint32(len(testTextData)), false),
+	))
+	copy(sector(testPL0Sector), directory(testPL0Sector, testRootSector,
+		directoryRecord("RDT", testRDTSector, discSectorDataSize, true),
+	))
+	copy(sector(testRDTSector), directory(testRDTSector, testPL0Sector,
+		directoryRecord("ROOM1000.RDT;1", testRoomSector, uint32(len(testRoomData)), false),
+	))
+	copy(data[testRoomSector*discSectorDataSize:], testRoomData)
+	copy(sector(testTextSector), testTextData)
+	return data
+}
+
+// writeDiscImage stores the user data in sectors of the size with the data at the offset.
+// The other bytes of every sector are filled, so they are read as garbage if they are not skipped.
+func writeDiscImage(t *testing.T, filename string, userData []byte, sectorSize int, dataOffset int) {
+	t.Helper()
+	var image bytes.Buffer
+	for start := 0; start < len(userData); start += discSectorDataSize {
+		sector := bytes.Repeat([]byte{0xaa}, sectorSize)
+		copy(sector[dataOffset:], userData[start:start+discSectorDataSize])
+		image.Write(sector)
+	}
+	if err := os.WriteFile(filename, image.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkDiscFiles(t *testing.T, filename string) {
+	t.Helper()
+	disc, err := OpenDiscImage(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer disc.Close()
+
+	files, err := disc.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.Path
+	}
+	if got, want := strings.Join(paths, ","), "PL0/RDT/ROOM1000.RDT,README.TXT"; got != want {
+		t.Errorf("%s: got files %s, want %s", filename, got, want)
+	}
+
+	reader, err := disc.Open("pl0/rdt/room1000.rdt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	room, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(room, testRoomData) {
+		t.Errorf("%s: room has %d bytes that differ from the %d bytes on the disc", filename, len(room), len(testRoomData))
+	}
+}
+
+func TestDiscImageSectorFormats(t *testing.T) {
+	for _, format := range discSectorFormats {
+		filename := filepath.Join(t.TempDir(), "disc.bin")
+		writeDiscImage(t, filename, buildDiscImage(discSectorDataSize), int(format.sectorSize), int(format.dataOffset))
+		checkDiscFiles(t, filename)
+	}
+}
+
+func TestCueSheetFileLines(t *testing.T) {
+	tests := []struct {
+		binFilename string
+		fileLine    string
+	}{
+		{"disc.bin", "FILE disc.bin BINARY"},
+		{"my disc.bin", `FILE "my disc.bin" BINARY`},
+		{"disc.bin", `file "disc.bin" binary`},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		writeDiscImage(t, filepath.Join(dir, test.binFilename), buildDiscImage(discSectorDataSize), 2352, 24)
+		cueFilename := filepath.Join(dir, "disc.cue")
+		cue := "REM test\n" + test.fileLine + "\n  TRACK 01 MODE2/2352\n    INDEX 01 00:00:00\n"
+		if err := os.WriteFile(cueFilename, []byte(cue), 0644); err != nil {
+			t.Fatal(err)
+		}
+		checkDiscFiles(t, cueFilename)
+	}
+}
+
+func TestCueSheetWithoutFile(t *testing.T) {
+	cueFilename := filepath.Join(t.TempDir(), "disc.cue")
+	if err := os.WriteFile(cueFilename, []byte("TRACK 01 MODE2/2352\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDiscImage(cueFilename); err == nil || !strings.Contains(err.Error(), "has no FILE") {
+		t.Errorf("got error %v, want a cue sheet without FILE", err)
+	}
+}
+
+func TestDiscDirectoryLargerThanImage(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "disc.iso")
+	writeDiscImage(t, filename, buildDiscImage(0x7fffffff), discSectorDataSize, 0)
+	disc, err := OpenDiscImage(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer disc.Close()
+
+	if _, err := disc.Files(); err == nil || !strings.Contains(err.Error(), "more than the rest of the image") {
+		t.Errorf("got error %v, want a directory larger than the image", err)
+	}
+}
@@ -0,0 +1,323 @@
+package fileio
+
+// Game profiles for the classic games whose rooms share the RDT and SCD formats
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+)
+
+//go:embed games/*.toml
+var gameSpecFiles embed.FS
+
+// Game is a game whose rooms the viewer can read. The games differ in the layout of the
+// RDT offset table and in the opcodes of the scripts.
+type Game struct {
+	Id     string // short name used on the command line, e.g. re3
+	Name   string
+	Layout RDTLayout // built-in layout, which a spec file for the game can replace
+
+	opcodeSpecFile string          // embedded opcode spec applied on top of the RE2 tables, empty for RE2
+	opcodes        *OpcodeSpecFile // parsed opcodeSpecFile
+	opcodeSet      *OpcodeSet      // opcodes with the loaded spec files applied, built when they are first needed
+	re2Opcodes     bool            // opcodes keep the meaning of the RE2 opcode with the same number unless a spec says otherwise, otherwise the spec replaces all opcodes
+}
+
+// RDTLayout is the layout of the header of an RDT file
+type RDTLayout struct {
+	HeaderSize   int            `json:"header_size" toml:"header_size"`                         // size of the header before the section offsets
+	NumOffsets   int            `json:"offsets" toml:"offsets"`                                 // number of section offsets after the header
+	Sections     map[string]int `json:"sections" toml:"sections"`                               // index of the offset of each section, keyed by the Section constants
+	ScriptBlocks bool           `json:"script_blocks,omitempty" toml:"script_blocks,omitempty"` // every script is one block that starts with its length instead of a table of function offsets
+}
+
+// re2Layout is the layout of RDTOffsets
+var re2Layout = RDTLayout{
+	HeaderSize: rdtHeaderSize,
+	NumOffsets: rdtNumOffsets,
+	Sections: map[string]int{
+		SectionInitScript:      RDT_SECTION_INIT_SCRIPT,
+		SectionRoomScript:      RDT_SECTION_EXECUTE_SCRIPT,
+		SectionLang1:           RDT_SECTION_LANG1,
+		SectionLang2:           RDT_SECTION_LANG2,
+		SectionCameraPositions: RDT_SECTION_CAMERA_POSITION,
+		SectionCameraSwitches:  RDT_SECTION_CAMERA_SWITCHES,
+		SectionCollision:       RDT_SECTION_COLLISION,
+	},
+}
+
+// re1Layout is the layout of the rooms of the original Resident Evil. The header before the offsets holds
+// the number of cameras at the same position as the RDTHeader. Only the scripts are read from these rooms.
+var re1Layout = RDTLayout{
+	HeaderSize: 72,
+	NumOffsets: 19,
+	Sections: map[string]int{
+		SectionInitScript: 6,
+		SectionRoomScript: 7,
+	},
+	ScriptBlocks: true,
+}
+
+var (
+	GameRE1 = &Game{Id: "re1", Name: "Resident Evil", Layout: re1Layout, opcodeSpecFile: "games/re1.toml"}
+	GameRE2 = &Game{Id: "re2", Name: "Resident Evil 2", Layout: re2Layout, re2Opcodes: true}
+
+	// RE3 runs on the RE2 engine and keeps its RDT layout and opcode numbers
+	GameRE3 = &Game{Id: "re3", Name: "Resident Evil 3", Layout: re2Layout, opcodeSpecFile: "games/re3.toml", re2Opcodes: true}
+)
+
+// Games are the supported games in the order in which DetectGame prefers them
+var Games = []*Game{GameRE2, GameRE3, GameRE1}
+
+// SelectedGame is the game of the rooms that are loaded, or nil to detect the game of every room
+var SelectedGame *Game
+
+// FindGame returns the game with the id, e.g. re3
+func FindGame(id string) (*Game, error) {
+	for _, game := range Games {
+		if strings.EqualFold(game.Id, id) {
+			return game, nil
+		}
+	}
+	ids := make([]string, len(Games))
+	for i, game := range Games {
+		ids[i] = game.Id
+	}
+	return nil, fmt.Errorf("unknown game %q, available games: %s", id, strings.Join(ids, ", "))
+}
+
+// Opcodes returns the opcodes of the game with the loaded spec files for the game applied
+func (game *Game) Opcodes() (*OpcodeSet, error) {
+	if game.opcodeSet == nil {
+		opcodes, err := newOpcodeSet(game)
+		if err != nil {
+			return nil, err
+		}
+		game.opcodeSet = opcodes
+	}
+	return game.opcodeSet, nil
+}
+
+// resetOpcodeSets builds the opcodes of every game again when they are needed next,
+// after spec files were loaded or removed. Loaded rooms keep the opcodes they were read with.
+func resetOpcodeSets() {
+	for _, game := range Games {
+		game.opcodeSet = nil
+	}
+}
+
+// userSpecs returns the loaded spec files for all games or for this game
+func (game *Game) userSpecs() []*OpcodeSpecFile {
+	specs := make([]*OpcodeSpecFile, 0, len(userOpcodeSpecs))
+	for _, userSpec := range userOpcodeSpecs {
+		if userSpec.Game == "" || userSpec.Game == game.Id {
+			specs = append(specs, userSpec)
+		}
+	}
+	return specs
+}
+
+// roomLayout returns the layout of the rooms of the game, which is replaced by the last
+// loaded spec file for the game with a layout
+func (game *Game) roomLayout() RDTLayout {
+	layout := game.Layout
+	for _, userSpec := range game.userSpecs() {
+		if userSpec.Layout != nil {
+			layout = *userSpec.Layout
+		}
+	}
+	return layout
+}
+
+// opcodeSpec returns the embedded opcodes of the game, or nil if the game uses the RE2 tables
+func (game *Game) opcodeSpec() (*OpcodeSpecFile, error) {
+	if game.opcodeSpecFile == "" || game.opcodes != nil {
+		return game.opcodes, nil
+	}
+	data, err := gameSpecFiles.ReadFile(game.opcodeSpecFile)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ReadOpcodeSpec(bytes.NewReader(data), "toml")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", game.opcodeSpecFile, err)
+	}
+	game.opcodes = spec
+	return spec, nil
+}
+
+// DetectGame returns the game of a room. Every game reads the offsets of the scripts from
+// the header and parses their functions with its instruction sizes. The game that parses
+// the most functions up to their end without unknown opcodes wins, RE2 if there is a tie.
+func DetectGame(r io.ReaderAt, fileLength int64) *Game {
+	bestGame, bestScore := GameRE2, -1
+	for _, game := range Games {
+		score := game.matchScore(r, fileLength)
+		if score > bestScore {
+			bestGame, bestScore = game, score
+		}
+	}
+	return bestGame
+}
+
+// matchScore counts the functions of the scripts that parse with the instruction sizes of the game,
+// minus a penalty for functions that do not. It is negative if the header does not fit the game.
+func (game *Game) matchScore(r io.ReaderAt, fileLength int64) int {
+	opcodes, err := game.Opcodes()
+	if err != nil {
+		return -1
+	}
+	layout := game.roomLayout()
+	offsets, err := readRDTOffsets(r, fileLength, layout)
+	if err != nil {
+		return -1
+	}
+
+	score := 0
+	for _, section := range []string{SectionInitScript, SectionRoomScript} {
+		offset := layout.sectionOffset(offsets, section)
+		if offset == 0 || int64(offset) >= fileLength {
+			return -1
+		}
+		script := io.NewSectionReader(r, int64(offset), fileLength-int64(offset))
+		var clean, broken int
+		if layout.ScriptBlocks {
+			clean, broken = countParsedBlock(script, fileLength-int64(offset), opcodes)
+		} else {
+			clean, broken = countParsedFunctions(script, fileLength-int64(offset), opcodes)
+		}
+		score += clean - 4*broken
+	}
+	return score
+}
+
+// countParsedFunctions walks the functions of a script with the instruction sizes and returns the number
+// of functions that end with EvtEnd and the number of functions with an unknown or truncated opcode
+func countParsedFunctions(script *io.SectionReader, length int64, opcodes *OpcodeSet) (int, int) {
+	firstOffset := uint16(0)
+	if err := binary.Read(script, binary.LittleEndian, &firstOffset); err != nil || firstOffset < 2 || int64(firstOffset) >= length {
+		return 0, 1
+	}
+	functionOffsets := make([]uint16, firstOffset/2)
+	functionOffsets[0] = firstOffset
+	if err := binary.Read(script, binary.LittleEndian, functionOffsets[1:]); err != nil {
+		return 0, 1
+	}
+
+	clean, broken := 0, 0
+	for functionNum, start := range functionOffsets {
+		end := length
+		if functionNum+1 < len(functionOffsets) {
+			end = int64(functionOffsets[functionNum+1])
+		}
+		position := int64(start)
+		for {
+			opcode := []byte{0}
+			if position >= end || position >= length {
+				broken++
+				break
+			}
+			if _, err := script.ReadAt(opcode, position); err != nil {
+				broken++
+				break
+			}
+			size, exists := opcodes.InstructionSize(opcode[0])
+			if !exists || position+int64(size) > end {
+				broken++
+				break
+			}
+			position += int64(size)
+			if opcodes.CanonicalOpcode(opcode[0]) == OP_EVT_END {
+				clean++
+				break
+			}
+		}
+	}
+	return clean, broken
+}
+
+// countParsedBlock walks a script block with the instruction sizes. It returns 1 clean block if the last
+// instruction ends at the end of the block, or 1 broken block otherwise.
+func countParsedBlock(script *io.SectionReader, length int64, opcodes *OpcodeSet) (int, int) {
+	blockLength := uint16(0)
+	if err := binary.Read(script, binary.LittleEndian, &blockLength); err != nil || blockLength == 0 {
+		return 0, 1
+	}
+	end := scriptBlockHeaderSize + int64(blockLength)
+	if end > length {
+		return 0, 1
+	}
+
+	position := int64(scriptBlockHeaderSize)
+	for position < end {
+		opcode := []byte{0}
+		if _, err := script.ReadAt(opcode, position); err != nil {
+			return 0, 1
+		}
+		size, exists := opcodes.InstructionSize(opcode[0])
+		if !exists {
+			return 0, 1
+		}
+		position += int64(size)
+	}
+	if position != end {
+		return 0, 1
+	}
+	return 1, 0
+}
+
+// readRDTOffsets reads the section offsets after the header
+func readRDTOffsets(r io.ReaderAt, fileLength int64, layout RDTLayout) ([]uint32, error) {
+	headerSize := int64(layout.HeaderSize)
+	if headerSize >= fileLength {
+		return nil, io.ErrUnexpectedEOF
+	}
+	reader := io.NewSectionReader(r, headerSize, fileLength-headerSize)
+	offsets := make([]uint32, layout.NumOffsets)
+	if err := binary.Read(reader, binary.LittleEndian, offsets); err != nil {
+		return nil, err
+	}
+	return offsets, nil
+}
+
+// validate checks that the layout has the offsets of its sections
+func (layout RDTLayout) validate() error {
+	if layout.HeaderSize < 0 || layout.NumOffsets < 1 {
+		return fmt.Errorf("invalid header size %d or number of offsets %d", layout.HeaderSize, layout.NumOffsets)
+	}
+	for section, index := range layout.Sections {
+		if !re2Layout.hasSection(section) {
+			return fmt.Errorf("unknown section %q", section)
+		}
+		if index < 0 || index >= layout.NumOffsets {
+			return fmt.Errorf("section %q has offset %d, but the header has %d offsets", section, index, layout.NumOffsets)
+		}
+	}
+	return nil
+}
+
+// patchable tells whether PatchRDT can rewrite rooms with the layout, which it reads as RDTOffsets
+func (layout RDTLayout) patchable() bool {
+	return !layout.ScriptBlocks && layout.HeaderSize == rdtHeaderSize && layout.NumOffsets == rdtNumOffsets &&
+		maps.Equal(layout.Sections, re2Layout.Sections)
+}
+
+// hasSection tells whether the rooms of the game contain the section
+func (layout RDTLayout) hasSection(section string) bool {
+	_, exists := layout.Sections[section]
+	return exists
+}
+
+// sectionOffset returns the offset of a section, or 0 if the game has no such section
+func (layout RDTLayout) sectionOffset(offsets []uint32, section string) uint32 {
+	index, exists := layout.Sections[section]
+	if !exists {
+		return 0
+	}
+	return offsets[index]
+}
@@ -3,6 +3,27 @@ package fileio
 // Script instruction struct definitions for Resident Evil 2 / Biohazard 2
 // These structs represent the binary layout of different script commands
 
+// ScriptInstrNoOp represents a NOP instruction (0x00)
+type ScriptInstrNoOp struct {
+	Opcode uint8 // 0x00
+}
+
+// ScriptInstrEventEnd represents an EVT_END instruction (0x01)
+type ScriptInstrEventEnd struct {
+	Opcode uint8 // 0x01
+}
+
+// ScriptInstrEventNext represents an EVT_NEXT instruction (0x02)
+type ScriptInstrEventNext struct {
+	Opcode uint8 // 0x02
+}
+
+// ScriptInstrEventChain represents an EVT_CHAIN instruction (0x03)
+type ScriptInstrEventChain struct {
+	Opcode uint8 // 0x03
+	Data   [3]uint8
+}
+
 // ScriptInstrEventExec represents an EVT_EXEC instruction (0x04)
 type ScriptInstrEventExec struct {
 	Opcode    uint8 // 0x04
@@ -11,6 +32,12 @@ type ScriptInstrEventExec struct {
 	Event     uint8
 }
 
+// ScriptInstrEventKill represents an EVT_KILL instruction (0x05)
+type ScriptInstrEventKill struct {
+	Opcode uint8 // 0x05
+	Event  uint8 // Event to stop
+}
+
 // ScriptInstrIfElseStart represents an IF_START instruction (0x06)
 type ScriptInstrIfElseStart struct {
 	Opcode      uint8 // 0x06
@@ -25,6 +52,11 @@ type ScriptInstrElseStart struct {
 	BlockLength uint16
 }
 
+// ScriptInstrEndIf represents an END_IF instruction (0x08)
+type ScriptInstrEndIf struct {
+	Opcode uint8 // 0x08
+}
+
 // ScriptInstrSleep represents a SLEEP instruction (0x09)
 type ScriptInstrSleep struct {
 	Opcode uint8 // 0x09
@@ -32,6 +64,22 @@ type ScriptInstrSleep struct {
 	Count  uint16
 }
 
+// ScriptInstrSleeping represents a SLEEPING instruction (0x0a)
+type ScriptInstrSleeping struct {
+	Opcode uint8  // 0x0a
+	Count  uint16 // Frames left to wait
+}
+
+// ScriptInstrWsleep represents a WSLEEP instruction (0x0b)
+type ScriptInstrWsleep struct {
+	Opcode uint8 // 0x0b
+}
+
+// ScriptInstrWsleeping represents a WSLEEPING instruction (0x0c)
+type ScriptInstrWsleeping struct {
+	Opcode uint8 // 0x0c
+}
+
 // ScriptInstrForStart represents a FOR instruction (0x0d)
 type ScriptInstrForStart struct {
 	Opcode      uint8 // 0x0d
@@ -40,6 +88,38 @@ type ScriptInstrForStart struct {
 	Count       uint16
 }
 
+// ScriptInstrForEnd represents a FOR_END instruction (0x0e)
+type ScriptInstrForEnd struct {
+	Opcode uint8 // 0x0e
+	Dummy  uint8
+}
+
+// ScriptInstrWhileStart represents a WHILE_START instruction (0x0f)
+type ScriptInstrWhileStart struct {
+	Opcode      uint8 // 0x0f
+	Dummy       uint8
+	BlockLength uint16
+}
+
+// ScriptInstrWhileEnd represents a WHILE_END instruction (0x10)
+type ScriptInstrWhileEnd struct {
+	Opcode uint8 // 0x10
+	Dummy  uint8
+}
+
+// ScriptInstrDoStart represents a DO_START instruction (0x11)
+type ScriptInstrDoStart struct {
+	Opcode      uint8 // 0x11
+	Dummy       uint8
+	BlockLength uint16
+}
+
+// ScriptInstrDoEnd represents a DO_END instruction (0x12)
+type ScriptInstrDoEnd struct {
+	Opcode uint8 // 0x12
+	Dummy  uint8
+}
+
 // ScriptInstrSwitch represents a SWITCH instruction (0x13)
 type ScriptInstrSwitch struct {
 	Opcode      uint8 // 0x13
@@ -55,6 +135,18 @@ type ScriptInstrSwitchCase struct {
 	Value       uint16
 }
 
+// ScriptInstrDefault represents a DEFAULT instruction (0x15)
+type ScriptInstrDefault struct {
+	Opcode uint8 // 0x15
+	Dummy  uint8
+}
+
+// ScriptInstrEndSwitch represents an END_SWITCH instruction (0x16)
+type ScriptInstrEndSwitch struct {
+	Opcode uint8 // 0x16
+	Dummy  uint8
+}
+
 // ScriptInstrGoto represents a GOTO instruction (0x17)
 type ScriptInstrGoto struct {
 	Opcode        uint8 // 0x17
@@ -70,6 +162,29 @@ type ScriptInstrGoSub struct {
 	Event  uint8
 }
 
+// ScriptInstrGoSubReturn represents a GOSUB_RETURN instruction (0x19)
+type ScriptInstrGoSubReturn struct {
+	Opcode uint8 // 0x19
+	Dummy  uint8
+}
+
+// ScriptInstrBreak represents a BREAK instruction (0x1a)
+type ScriptInstrBreak struct {
+	Opcode uint8 // 0x1a
+	Dummy  uint8
+}
+
+// ScriptInstrWorkCopy represents a WORK_COPY instruction (0x1d)
+type ScriptInstrWorkCopy struct {
+	Opcode uint8 // 0x1d
+	Data   [3]uint8
+}
+
+// ScriptInstrNoOp2 represents a NOP2 instruction (0x20)
+type ScriptInstrNoOp2 struct {
+	Opcode uint8 // 0x20
+}
+
 // ScriptInstrCheckBitTest represents a CHECK instruction (0x21)
 type ScriptInstrCheckBitTest struct {
 	Opcode    uint8 // 0x21
@@ -86,6 +201,13 @@ type ScriptInstrSetBit struct {
 	Operation uint8 // 0x0: clear, 0x1: set, 0x2-0x6: invalid, 0x7: flip bit
 }
 
+// Operations of the SET_BIT instruction (0x22)
+const (
+	SET_BIT_CLEAR = 0
+	SET_BIT_SET   = 1
+	SET_BIT_FLIP  = 7
+)
+
 // ScriptInstrCompare represents a COMPARE instruction (0x23)
 type ScriptInstrCompare struct {
 	Opcode    uint8 // 0x23
@@ -95,6 +217,17 @@ type ScriptInstrCompare struct {
 	Value     int16 // Value to compare against
 }
 
+// Comparison operations of the COMPARE (0x23) and MEMBER_CMP (0x3e) instructions
+const (
+	CMP_EQ  = 0 // ==
+	CMP_GT  = 1 // >
+	CMP_GE  = 2 // >=
+	CMP_LT  = 3 // <
+	CMP_LE  = 4 // <=
+	CMP_NE  = 5 // !=
+	CMP_AND = 6 // bitwise and is not zero
+)
+
 // ScriptInstrSave represents a SAVE instruction (0x24)
 type ScriptInstrSave struct {
 	Opcode uint8 // 0x24
@@ -115,7 +248,7 @@ type ScriptInstrCalc struct {
 	Dummy     uint8
 	Operation uint8
 	VarId     uint8
-	Value     uint8
+	Value     int16
 }
 
 // ScriptInstrCalc2 represents a CALC2 instruction (0x27)
@@ -126,12 +259,31 @@ type ScriptInstrCalc2 struct {
 	SourceVarId uint8
 }
 
+// ScriptInstrSceRnd represents a SCE_RND instruction (0x28)
+type ScriptInstrSceRnd struct {
+	Opcode uint8 // 0x28
+}
+
 // ScriptInstrCutChg represents a CUT_CHG instruction (0x29)
 type ScriptInstrCutChg struct {
 	Opcode   uint8 // 0x29
 	CameraId uint8
 }
 
+// ScriptInstrCutOld represents a CUT_OLD instruction (0x2a)
+type ScriptInstrCutOld struct {
+	Opcode uint8 // 0x2a
+}
+
+// ScriptInstrMessageOn represents a MESSAGE_ON instruction (0x2b)
+type ScriptInstrMessageOn struct {
+	Opcode   uint8 // 0x2b
+	Dummy    uint8
+	Id       uint8 // Index of message in the room's message block
+	Unknown0 uint8
+	Unknown1 uint16
+}
+
 // ScriptInstrAotSet represents an AOT_SET instruction (0x2c)
 type ScriptInstrAotSet struct {
 	Opcode       uint8 // 0x2c
@@ -171,6 +323,23 @@ type ScriptInstrWorkSet struct {
 	Index     uint8
 }
 
+// ScriptInstrSpeedSet represents a SPEED_SET instruction (0x2f)
+type ScriptInstrSpeedSet struct {
+	Opcode uint8 // 0x2f
+	Id     uint8
+	Value  int16
+}
+
+// ScriptInstrAddSpeed represents an ADD_SPEED instruction (0x30)
+type ScriptInstrAddSpeed struct {
+	Opcode uint8 // 0x30
+}
+
+// ScriptInstrAddAspeed represents an ADD_ASPEED instruction (0x31)
+type ScriptInstrAddAspeed struct {
+	Opcode uint8 // 0x31
+}
+
 // ScriptInstrPosSet represents a POS_SET instruction (0x32)
 type ScriptInstrPosSet struct {
 	Opcode uint8 // 0x32
@@ -180,6 +349,15 @@ type ScriptInstrPosSet struct {
 	Z      int16
 }
 
+// ScriptInstrDirSet represents a DIR_SET instruction (0x33)
+type ScriptInstrDirSet struct {
+	Opcode uint8 // 0x33
+	Dummy  uint8
+	X      int16
+	Y      int16
+	Z      int16
+}
+
 // ScriptInstrMemberSet represents a MEMBER_SET instruction (0x34)
 type ScriptInstrMemberSet struct {
 	Opcode      uint8 // 0x34
@@ -187,6 +365,22 @@ type ScriptInstrMemberSet struct {
 	Value       uint16
 }
 
+// ScriptInstrMemberSet2 represents a MEMBER_SET2 instruction (0x35)
+type ScriptInstrMemberSet2 struct {
+	Opcode      uint8 // 0x35
+	MemberIndex uint8
+	VarId       uint8 // Variable holding the new value
+}
+
+// ScriptInstrSeOn represents an SE_ON instruction (0x36)
+type ScriptInstrSeOn struct {
+	Opcode  uint8 // 0x36
+	VabId   uint8 // Sound bank
+	Edt     int16 // Sound effect in the bank
+	Data    int16
+	X, Y, Z int16 // Position of the sound
+}
+
 // ScriptInstrScaIdSet represents a SCA_ID_SET instruction (0x37)
 type ScriptInstrScaIdSet struct {
 	Opcode uint8 // 0x37
@@ -194,6 +388,14 @@ type ScriptInstrScaIdSet struct {
 	Flag   uint16
 }
 
+// ScriptInstrDirCk represents a DIR_CK instruction (0x39)
+type ScriptInstrDirCk struct {
+	Opcode uint8 // 0x39
+	Dummy  uint8
+	X, Z   int16
+	Add    int16
+}
+
 // ScriptInstrSceEsprOn represents a SCE_ESPR_ON instruction (0x3a)
 type ScriptInstrSceEsprOn struct {
 	Opcode   uint8 // 0x3a
@@ -233,6 +435,13 @@ type ScriptInstrCutAuto struct {
 	FlagOn uint8
 }
 
+// ScriptInstrMemberCopy represents a MEMBER_COPY instruction (0x3d)
+type ScriptInstrMemberCopy struct {
+	Opcode      uint8 // 0x3d
+	VarId       uint8 // Variable that receives the value
+	MemberIndex uint8
+}
+
 // ScriptInstrMemberCompare represents a MEMBER_CMP instruction (0x3e)
 type ScriptInstrMemberCompare struct {
 	Opcode           uint8 // 0x3e
@@ -270,6 +479,11 @@ type ScriptInstrPlcNeck struct {
 	Unknown   [2]int8
 }
 
+// ScriptInstrPlcRet represents a PLC_RET instruction (0x42)
+type ScriptInstrPlcRet struct {
+	Opcode uint8 // 0x42
+}
+
 // ScriptInstrPlcFlag represents a PLC_FLAG instruction (0x43)
 type ScriptInstrPlcFlag struct {
 	Opcode    uint8 // 0x43
@@ -304,6 +518,22 @@ type ScriptInstrAotReset struct {
 	Data   [6]uint8
 }
 
+// ScriptInstrAotOn represents an AOT_ON instruction (0x47)
+type ScriptInstrAotOn struct {
+	Opcode uint8 // 0x47
+	Aot    uint8 // AOT to trigger
+}
+
+// ScriptInstrSuperSet represents a SUPER_SET instruction (0x48)
+type ScriptInstrSuperSet struct {
+	Opcode    uint8 // 0x48
+	Dummy     uint8
+	Work      uint8
+	Id        uint8
+	Position  [3]int16
+	Direction [3]int16
+}
+
 // ScriptInstrSceEsprKill represents a SCE_ESPR_KILL instruction (0x4c)
 type ScriptInstrSceEsprKill struct {
 	Opcode        uint8 // 0x4c
@@ -313,6 +543,13 @@ type ScriptInstrSceEsprKill struct {
 	WorkIndex     uint8
 }
 
+// ScriptInstrCutReplace represents a CUT_REPLACE instruction (0x4b)
+type ScriptInstrCutReplace struct {
+	Opcode       uint8 // 0x4b
+	FromCameraId uint8 // Camera that is replaced
+	ToCameraId   uint8 // Camera shown instead
+}
+
 // ScriptInstrDoorModelSet represents a DOOR_MODEL_SET instruction (0x4d)
 type ScriptInstrDoorModelSet struct {
 	Opcode      uint8 // 0x4d
@@ -344,6 +581,12 @@ type ScriptInstrItemAotSet struct {
 	Act             uint8
 }
 
+// ScriptInstrSceTrgCk represents a SCE_TRG_CK instruction (0x50)
+type ScriptInstrSceTrgCk struct {
+	Opcode uint8 // 0x50
+	Data   [3]uint8
+}
+
 // ScriptInstrSceBgmControl represents a SCE_BGM_CONTROL instruction (0x51)
 type ScriptInstrSceBgmControl struct {
 	Opcode      uint8 // 0x51
@@ -364,6 +607,12 @@ type ScriptInstrSceEsprControl struct {
 	WorkIndex     uint8
 }
 
+// ScriptInstrSceFadeSet represents a SCE_FADE_SET instruction (0x53)
+type ScriptInstrSceFadeSet struct {
+	Opcode uint8 // 0x53
+	Data   [5]uint8
+}
+
 // ScriptInstrSceEspr3DOn represents a SCE_ESPR3D_ON instruction (0x54)
 type ScriptInstrSceEspr3DOn struct {
 	Opcode   uint8 // 0x54
@@ -376,6 +625,16 @@ type ScriptInstrSceEspr3DOn struct {
 	DirY     uint16
 }
 
+// ScriptInstrSceBgmTblSet represents a SCE_BGMTBL_SET instruction (0x57)
+type ScriptInstrSceBgmTblSet struct {
+	Opcode uint8 // 0x57
+	Dummy  uint8
+	Room   uint8
+	Stage  uint8
+	Data0  uint16
+	Data1  uint16
+}
+
 // ScriptInstrPlcRot represents a PLC_ROT instruction (0x58)
 type ScriptInstrPlcRot struct {
 	Opcode uint8 // 0x58
@@ -390,12 +649,42 @@ type ScriptInstrXaOn struct {
 	Id      int16 // ID of sound to play
 }
 
+// ScriptInstrWeaponChg represents a WEAPON_CHG instruction (0x5a)
+type ScriptInstrWeaponChg struct {
+	Opcode   uint8 // 0x5a
+	WeaponId uint8
+}
+
+// ScriptInstrPlcCnt represents a PLC_CNT instruction (0x5b)
+type ScriptInstrPlcCnt struct {
+	Opcode uint8 // 0x5b
+	Count  uint8
+}
+
+// ScriptInstrSceShakeOn represents a SCE_SHAKE_ON instruction (0x5c)
+type ScriptInstrSceShakeOn struct {
+	Opcode uint8 // 0x5c
+	Data   [2]uint8
+}
+
 // ScriptInstrMizuDivSet represents a MIZU_DIV_SET instruction (0x5d)
 type ScriptInstrMizuDivSet struct {
 	Opcode     uint8 // 0x5d
 	MizuDivMax uint8
 }
 
+// ScriptInstrKeepItemCk represents a KEEP_ITEM_CK instruction (0x5e)
+type ScriptInstrKeepItemCk struct {
+	Opcode uint8 // 0x5e
+	ItemId uint8 // Item that has to be in the inventory
+}
+
+// ScriptInstrXaVol represents a XA_VOL instruction (0x5f)
+type ScriptInstrXaVol struct {
+	Opcode uint8 // 0x5f
+	Volume uint8
+}
+
 // ScriptInstrKageSet represents a KAGE_SET instruction (0x60)
 type ScriptInstrKageSet struct {
 	Opcode           uint8 // 0x60
@@ -406,6 +695,40 @@ type ScriptInstrKageSet struct {
 	OffsetX, OffsetZ int16
 }
 
+// ScriptInstrCutBeSet represents a CUT_BE_SET instruction (0x61)
+type ScriptInstrCutBeSet struct {
+	Opcode uint8 // 0x61
+	Data   [3]uint8
+}
+
+// ScriptInstrSceItemLost represents a SCE_ITEM_LOST instruction (0x62)
+type ScriptInstrSceItemLost struct {
+	Opcode uint8 // 0x62
+	ItemId uint8 // Item removed from the inventory
+}
+
+// ScriptInstrPlcGunEff represents a PLC_GUN_EFF instruction (0x63)
+type ScriptInstrPlcGunEff struct {
+	Opcode uint8 // 0x63
+}
+
+// ScriptInstrSceEsprOn2 represents a SCE_ESPR_ON2 instruction (0x64)
+type ScriptInstrSceEsprOn2 struct {
+	Opcode uint8 // 0x64
+	Data   [15]uint8
+}
+
+// ScriptInstrSceEsprKill2 represents a SCE_ESPR_KILL2 instruction (0x65)
+type ScriptInstrSceEsprKill2 struct {
+	Opcode uint8 // 0x65
+	Id     uint8
+}
+
+// ScriptInstrPlcStop represents a PLC_STOP instruction (0x66)
+type ScriptInstrPlcStop struct {
+	Opcode uint8 // 0x66
+}
+
 // ScriptInstrAotSet4p represents an AOT_SET_4P instruction (0x67)
 type ScriptInstrAotSet4p struct {
 	Opcode uint8 // 0x67
@@ -463,13 +786,70 @@ type ScriptInstrItemAotSet4p struct {
 	Act             uint8
 }
 
+// ScriptInstrLightPosSet represents a LIGHT_POS_SET instruction (0x6a)
+type ScriptInstrLightPosSet struct {
+	Opcode uint8 // 0x6a
+	Dummy  uint8
+	Index  uint8 // Light to move
+	Axis   uint8 // 0: x, 1: y, 2: z
+	Value  int16
+}
+
+// ScriptInstrLightKidoSet represents a LIGHT_KIDO_SET instruction (0x6b)
+type ScriptInstrLightKidoSet struct {
+	Opcode     uint8 // 0x6b
+	Index      uint8
+	Luminosity int16
+}
+
+// ScriptInstrRbjReset represents a RBJ_RESET instruction (0x6c)
+type ScriptInstrRbjReset struct {
+	Opcode uint8 // 0x6c
+}
+
+// ScriptInstrSceScrMove represents a SCE_SCR_MOVE instruction (0x6d)
+type ScriptInstrSceScrMove struct {
+	Opcode uint8 // 0x6d
+	Dummy  uint8
+	Value  int16
+}
+
+// ScriptInstrPartsSet represents a PARTS_SET instruction (0x6e)
+type ScriptInstrPartsSet struct {
+	Opcode uint8 // 0x6e
+	Dummy  uint8
+	Id     uint8
+	Type   uint8
+	Value  int16
+}
+
+// ScriptInstrMovieOn represents a MOVIE_ON instruction (0x6f)
+type ScriptInstrMovieOn struct {
+	Opcode uint8 // 0x6f
+	Id     uint8
+}
+
+// ScriptInstrScePartsBomb represents a SCE_PARTS_BOMB instruction (0x7a)
+type ScriptInstrScePartsBomb struct {
+	Opcode uint8 // 0x7a
+	Data   [15]uint8
+}
+
+// ScriptInstrScePartsDown represents a SCE_PARTS_DOWN instruction (0x7b)
+type ScriptInstrScePartsDown struct {
+	Opcode uint8 // 0x7b
+	Data   [15]uint8
+}
+
 // SCDOutput represents the parsed output from a script data file
 type SCDOutput struct {
-	ScriptData ScriptFunction
+	ScriptData  ScriptFunction
+	Diagnostics []*Diagnostic // problems of the script with offsets relative to its start
 }
 
 // ScriptFunction represents a parsed script function with its instructions
 type ScriptFunction struct {
 	Instructions        map[int][]byte // key is program counter, value is command
 	StartProgramCounter []int          // set per function
+	Program             []Instruction  // decoded instructions in program counter order
 }
@@ -4,21 +4,23 @@ package fileio
 // This provides function signatures, parameter hints, and documentation for script opcodes
 
 import (
-	"bytes"
-	"encoding/binary"
 	"fmt"
 	"strings"
 )
 
-// Opcode signature function type
-type OpcodeSignature func([]byte) string
+// Opcode signature function type, which formats the parameters of a decoded instruction
+type OpcodeSignature func(Instruction) string
 
-// Helper function to read binary data into a struct
-func readInstruction[T any](lineBytes []byte) T {
-	var instruction T
-	byteArr := bytes.NewBuffer(lineBytes)
-	binary.Read(byteArr, binary.LittleEndian, &instruction)
-	return instruction
+// signatureOf turns a function that formats the struct of an opcode into an OpcodeSignature.
+// An instruction that was decoded into another struct, e.g. by a spec file, is printed as bytes.
+func signatureOf[T any](format func(T) string) OpcodeSignature {
+	return func(instruction Instruction) string {
+		decoded, ok := instruction.Decoded().(T)
+		if !ok {
+			return formatDefaultParams(instruction.Bytes())
+		}
+		return format(decoded)
+	}
 }
 
 // Helper function to format array data
@@ -41,38 +43,55 @@ func formatCoords3D(x, y, z int16) string {
 	return fmt.Sprintf("[%d, %d, %d]", x, y, z)
 }
 
+// Helper functions to show the names of the symbol table after parameters
+func formatItemComment(itemId int) string {
+	return formatSymbolComment(Symbols.Item(itemId))
+}
+
+func formatBitArrayComment(bitArray uint8) string {
+	return formatSymbolComment(Symbols.BitArray(int(bitArray)))
+}
+
+func formatFlagComment(bitArray uint8, bitNumber uint8) string {
+	return formatSymbolComment(Symbols.Flag(int(bitArray), int(bitNumber)))
+}
+
+func formatStageComment(stage uint8) string {
+	return formatSymbolComment(Symbols.Stage(int(stage)))
+}
+
+func formatRoomComment(stage uint8, room uint8) string {
+	return formatSymbolComment(Symbols.Room(stage, room))
+}
+
 // Individual opcode signature generators for each opcode
-func formatGosubParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrGoSub](lineBytes)
+func formatGosubParams(instruction ScriptInstrGoSub) string {
 	return fmt.Sprintf("Event=%d", instruction.Event)
 }
 
-func formatCheckBitParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrCheckBitTest](lineBytes)
-	return fmt.Sprintf("BitArray=%d, BitNumber=%d, Value=%d",
-		instruction.BitArray, instruction.BitNumber, instruction.Value)
+func formatCheckBitParams(instruction ScriptInstrCheckBitTest) string {
+	return fmt.Sprintf("BitArray=%d%s, BitNumber=%d%s, Value=%d",
+		instruction.BitArray, formatBitArrayComment(instruction.BitArray),
+		instruction.BitNumber, formatFlagComment(instruction.BitArray, instruction.BitNumber), instruction.Value)
 }
 
-func formatSetBitParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSetBit](lineBytes)
-	return fmt.Sprintf("BitArray=%d, BitNumber=%d, Operation=%d",
-		instruction.BitArray, instruction.BitNumber, instruction.Operation)
+func formatSetBitParams(instruction ScriptInstrSetBit) string {
+	return fmt.Sprintf("BitArray=%d%s, BitNumber=%d%s, Operation=%d",
+		instruction.BitArray, formatBitArrayComment(instruction.BitArray),
+		instruction.BitNumber, formatFlagComment(instruction.BitArray, instruction.BitNumber), instruction.Operation)
 }
 
-func formatCutChgParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrCutChg](lineBytes)
+func formatCutChgParams(instruction ScriptInstrCutChg) string {
 	return fmt.Sprintf("CameraId=%d", instruction.CameraId)
 }
 
-func formatAotSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrAotSet](lineBytes)
+func formatAotSetParams(instruction ScriptInstrAotSet) string {
 	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X=%d, Z=%d, Width=%d, Depth=%d, Data=%s",
 		instruction.Aot, instruction.Id, instruction.Type, instruction.Floor, instruction.Super,
 		instruction.X, instruction.Z, instruction.Width, instruction.Depth, formatArray(instruction.Data[:]))
 }
 
-func formatObjModelSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrObjModelSet](lineBytes)
+func formatObjModelSetParams(instruction ScriptInstrObjModelSet) string {
 	return fmt.Sprintf("ObjectIndex=%d, ObjectId=%d, Counter=%d, Wait=%d, Num=%d, Floor=%d, Flag0=%d, Type=%d, Flag1=%d, Attribute=%d, Position=%s, Direction=%s, Offset=%s, Dimensions=%s",
 		instruction.ObjectIndex, instruction.ObjectId, instruction.Counter, instruction.Wait, instruction.Num,
 		instruction.Floor, instruction.Flag0, instruction.Type, instruction.Flag1, instruction.Attribute,
@@ -82,206 +101,176 @@ func formatObjModelSetParams(lineBytes []byte) string {
 		fmt.Sprintf("[%d, %d, %d]", instruction.Dimensions[0], instruction.Dimensions[1], instruction.Dimensions[2]))
 }
 
-func formatPosSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrPosSet](lineBytes)
+func formatPosSetParams(instruction ScriptInstrPosSet) string {
 	return fmt.Sprintf("Dummy=%d, X=%d, Y=%d, Z=%d",
 		instruction.Dummy, instruction.X, instruction.Y, instruction.Z)
 }
 
-func formatSceEsprOnParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSceEsprOn](lineBytes)
+func formatSceEsprOnParams(instruction ScriptInstrSceEsprOn) string {
 	return fmt.Sprintf("Dummy=%d, Id=%d, Type=%d, Work=%d, Unknown1=%d, X=%d, Y=%d, Z=%d, DirY=%d",
 		instruction.Dummy, instruction.Id, instruction.Type, instruction.Work, instruction.Unknown1,
 		instruction.X, instruction.Y, instruction.Z, instruction.DirY)
 }
 
-func formatDoorAotSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrDoorAotSet](lineBytes)
-	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X=%d, Z=%d, Width=%d, Depth=%d, NextX=%d, NextY=%d, NextZ=%d, NextDir=%d, Stage=%d, Room=%d, Camera=%d, NextFloor=%d, TextureType=%d, DoorType=%d, KnockType=%d, KeyId=%d, KeyType=%d, Free=%d",
+func formatDoorAotSetParams(instruction ScriptInstrDoorAotSet) string {
+	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X=%d, Z=%d, Width=%d, Depth=%d, NextX=%d, NextY=%d, NextZ=%d, NextDir=%d, Stage=%d%s, Room=%d%s, Camera=%d, NextFloor=%d, TextureType=%d, DoorType=%d, KnockType=%d, KeyId=%d%s, KeyType=%d, Free=%d",
 		instruction.Aot, instruction.Id, instruction.Type, instruction.Floor, instruction.Super,
 		instruction.X, instruction.Z, instruction.Width, instruction.Depth,
 		instruction.NextX, instruction.NextY, instruction.NextZ, instruction.NextDir,
-		instruction.Stage, instruction.Room, instruction.Camera, instruction.NextFloor,
-		instruction.TextureType, instruction.DoorType, instruction.KnockType,
-		instruction.KeyId, instruction.KeyType, instruction.Free)
+		instruction.Stage, formatStageComment(instruction.Stage),
+		instruction.Room, formatRoomComment(instruction.Stage, instruction.Room),
+		instruction.Camera, instruction.NextFloor, instruction.TextureType, instruction.DoorType, instruction.KnockType,
+		instruction.KeyId, formatItemComment(int(instruction.KeyId)), instruction.KeyType, instruction.Free)
 }
 
-func formatPlcNeckParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrPlcNeck](lineBytes)
+func formatPlcNeckParams(instruction ScriptInstrPlcNeck) string {
 	return fmt.Sprintf("Operation=%d, NeckX=%d, NeckY=%d, NeckZ=%d, Unknown=%s",
 		instruction.Operation, instruction.NeckX, instruction.NeckY, instruction.NeckZ,
 		formatArray([]uint8{uint8(instruction.Unknown[0]), uint8(instruction.Unknown[1])}))
 }
 
-func formatSceEmSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSceEmSet](lineBytes)
-	return fmt.Sprintf("Dummy=%d, Aot=%d, Id=%d, Type=%d, Status=%d, Floor=%d, SoundFlag=%d, ModelType=%d, EmSetFlag=%d, X=%d, Y=%d, Z=%d, DirY=%d, Motion=%d, CtrFlag=%d",
-		instruction.Dummy, instruction.Aot, instruction.Id, instruction.Type, instruction.Status,
+func formatSceEmSetParams(instruction ScriptInstrSceEmSet) string {
+	return fmt.Sprintf("Dummy=%d, Aot=%d, Id=%d, Type=%d%s, Status=%d, Floor=%d, SoundFlag=%d, ModelType=%d, EmSetFlag=%d, X=%d, Y=%d, Z=%d, DirY=%d, Motion=%d, CtrFlag=%d",
+		instruction.Dummy, instruction.Aot, instruction.Id, instruction.Type, formatSymbolComment(Symbols.Enemy(int(instruction.Type))), instruction.Status,
 		instruction.Floor, instruction.SoundFlag, instruction.ModelType, instruction.EmSetFlag,
 		instruction.X, instruction.Y, instruction.Z, instruction.DirY, instruction.Motion, instruction.CtrFlag)
 }
 
-func formatAotResetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrAotReset](lineBytes)
+func formatAotResetParams(instruction ScriptInstrAotReset) string {
 	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Data=%s",
 		instruction.Aot, instruction.Id, instruction.Type, formatArray(instruction.Data[:]))
 }
 
-func formatItemAotSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrItemAotSet](lineBytes)
-	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X=%d, Z=%d, Width=%d, Depth=%d, ItemId=%d, Amount=%d, ItemPickedIndex=%d, Md1ModelId=%d, Act=%d",
+func formatItemAotSetParams(instruction ScriptInstrItemAotSet) string {
+	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X=%d, Z=%d, Width=%d, Depth=%d, ItemId=%d%s, Amount=%d, ItemPickedIndex=%d, Md1ModelId=%d, Act=%d",
 		instruction.Aot, instruction.Id, instruction.Type, instruction.Floor, instruction.Super,
 		instruction.X, instruction.Z, instruction.Width, instruction.Depth,
-		instruction.ItemId, instruction.Amount, instruction.ItemPickedIndex, instruction.Md1ModelId, instruction.Act)
+		instruction.ItemId, formatItemComment(int(instruction.ItemId)),
+		instruction.Amount, instruction.ItemPickedIndex, instruction.Md1ModelId, instruction.Act)
 }
 
-func formatSceBgmControlParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSceBgmControl](lineBytes)
+func formatSceBgmControlParams(instruction ScriptInstrSceBgmControl) string {
 	return fmt.Sprintf("Id=%d, Operation=%d, Type=%d, LeftVolume=%d, RightVolume=%d",
 		instruction.Id, instruction.Operation, instruction.Type, instruction.LeftVolume, instruction.RightVolume)
 }
 
-func formatAotSet4pParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrAotSet4p](lineBytes)
+func formatAotSet4pParams(instruction ScriptInstrAotSet4p) string {
 	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X1=%d, Z1=%d, X2=%d, Z2=%d, X3=%d, Z3=%d, X4=%d, Z4=%d, Data=%s",
 		instruction.Aot, instruction.Id, instruction.Type, instruction.Floor, instruction.Super,
 		instruction.X1, instruction.Z1, instruction.X2, instruction.Z2,
 		instruction.X3, instruction.Z3, instruction.X4, instruction.Z4, formatArray(instruction.Data[:]))
 }
 
-func formatEventExecParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrEventExec](lineBytes)
+func formatEventExecParams(instruction ScriptInstrEventExec) string {
 	return fmt.Sprintf("ThreadNum=%d, ExOpcode=%d, Event=%d",
 		instruction.ThreadNum, instruction.ExOpcode, instruction.Event)
 }
 
-func formatIfElseStartParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrIfElseStart](lineBytes)
+func formatIfElseStartParams(instruction ScriptInstrIfElseStart) string {
 	return fmt.Sprintf("Dummy=%d, BlockLength=%d",
 		instruction.Dummy, instruction.BlockLength)
 }
 
-func formatElseStartParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrElseStart](lineBytes)
+func formatElseStartParams(instruction ScriptInstrElseStart) string {
 	return fmt.Sprintf("Dummy=%d, BlockLength=%d",
 		instruction.Dummy, instruction.BlockLength)
 }
 
-func formatSleepParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSleep](lineBytes)
+func formatSleepParams(instruction ScriptInstrSleep) string {
 	return fmt.Sprintf("Dummy=%d, Count=%d",
 		instruction.Dummy, instruction.Count)
 }
 
-func formatForStartParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrForStart](lineBytes)
+func formatForStartParams(instruction ScriptInstrForStart) string {
 	return fmt.Sprintf("Dummy=%d, BlockLength=%d, Count=%d",
 		instruction.Dummy, instruction.BlockLength, instruction.Count)
 }
 
-func formatSwitchParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSwitch](lineBytes)
+func formatSwitchParams(instruction ScriptInstrSwitch) string {
 	return fmt.Sprintf("VarId=%d, BlockLength=%d",
 		instruction.VarId, instruction.BlockLength)
 }
 
-func formatSwitchCaseParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSwitchCase](lineBytes)
+func formatSwitchCaseParams(instruction ScriptInstrSwitchCase) string {
 	return fmt.Sprintf("Dummy=%d, BlockLength=%d, Value=%d",
 		instruction.Dummy, instruction.BlockLength, instruction.Value)
 }
 
-func formatGotoParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrGoto](lineBytes)
+func formatGotoParams(instruction ScriptInstrGoto) string {
 	return fmt.Sprintf("IfElseCounter=%d, LoopLevel=%d, Unknown=%d, Offset=%d",
 		instruction.IfElseCounter, instruction.LoopLevel, instruction.Unknown, instruction.Offset)
 }
 
-func formatCompareParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrCompare](lineBytes)
+func formatCompareParams(instruction ScriptInstrCompare) string {
 	return fmt.Sprintf("Dummy=%d, VarId=%d, Operation=%d, Value=%d",
 		instruction.Dummy, instruction.VarId, instruction.Operation, instruction.Value)
 }
 
-func formatSaveParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSave](lineBytes)
+func formatSaveParams(instruction ScriptInstrSave) string {
 	return fmt.Sprintf("VarId=%d, Value=%d",
 		instruction.VarId, instruction.Value)
 }
 
-func formatCopyParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrCopy](lineBytes)
+func formatCopyParams(instruction ScriptInstrCopy) string {
 	return fmt.Sprintf("DestVarId=%d, SourceVarId=%d",
 		instruction.DestVarId, instruction.SourceVarId)
 }
 
-func formatCalcParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrCalc](lineBytes)
+func formatCalcParams(instruction ScriptInstrCalc) string {
 	return fmt.Sprintf("Dummy=%d, Operation=%d, VarId=%d, Value=%d",
 		instruction.Dummy, instruction.Operation, instruction.VarId, instruction.Value)
 }
 
-func formatCalc2Params(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrCalc2](lineBytes)
+func formatCalc2Params(instruction ScriptInstrCalc2) string {
 	return fmt.Sprintf("Operation=%d, VarId=%d, SourceVarId=%d",
 		instruction.Operation, instruction.VarId, instruction.SourceVarId)
 }
 
-func formatWorkSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrWorkSet](lineBytes)
+func formatWorkSetParams(instruction ScriptInstrWorkSet) string {
 	return fmt.Sprintf("Component=%d, Index=%d",
 		instruction.Component, instruction.Index)
 }
 
-func formatMemberSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrMemberSet](lineBytes)
+func formatMemberSetParams(instruction ScriptInstrMemberSet) string {
 	return fmt.Sprintf("MemberIndex=%d, Value=%d",
 		instruction.MemberIndex, instruction.Value)
 }
 
-func formatScaIdSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrScaIdSet](lineBytes)
+func formatScaIdSetParams(instruction ScriptInstrScaIdSet) string {
 	return fmt.Sprintf("Id=%d, Flag=%d",
 		instruction.Id, instruction.Flag)
 }
 
-func formatCutAutoParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrCutAuto](lineBytes)
+func formatCutAutoParams(instruction ScriptInstrCutAuto) string {
 	return fmt.Sprintf("FlagOn=%d", instruction.FlagOn)
 }
 
-func formatMemberCompareParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrMemberCompare](lineBytes)
+func formatMemberCompareParams(instruction ScriptInstrMemberCompare) string {
 	return fmt.Sprintf("Unknown0=%d, MemberIndex=%d, CompareOperation=%d, Value=%d",
 		instruction.Unknown0, instruction.MemberIndex, instruction.CompareOperation, instruction.Value)
 }
 
-func formatPlcMotionParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrPlcMotion](lineBytes)
+func formatPlcMotionParams(instruction ScriptInstrPlcMotion) string {
 	return fmt.Sprintf("Action=%d, MoveNumber=%d, SceneFlag=%d",
 		instruction.Action, instruction.MoveNumber, instruction.SceneFlag)
 }
 
-func formatPlcDestParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrPlcDest](lineBytes)
+func formatPlcDestParams(instruction ScriptInstrPlcDest) string {
 	return fmt.Sprintf("Dummy=%d, Action=%d, FlagNumber=%d, DestX=%d, DestZ=%d",
 		instruction.Dummy, instruction.Action, instruction.FlagNumber, instruction.DestX, instruction.DestZ)
 }
 
-func formatPlcFlagParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrPlcFlag](lineBytes)
+func formatPlcFlagParams(instruction ScriptInstrPlcFlag) string {
 	return fmt.Sprintf("Operation=%d, Flag=%d",
 		instruction.Operation, instruction.Flag)
 }
 
-func formatSceEsprKillParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSceEsprKill](lineBytes)
+func formatSceEsprKillParams(instruction ScriptInstrSceEsprKill) string {
 	return fmt.Sprintf("Id=%d, Type=%d, WorkComponent=%d, WorkIndex=%d",
 		instruction.Id, instruction.Type, instruction.WorkComponent, instruction.WorkIndex)
 }
 
-func formatDoorModelSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrDoorModelSet](lineBytes)
+func formatDoorModelSetParams(instruction ScriptInstrDoorModelSet) string {
 	return fmt.Sprintf("Index=%d, Id=%d, Type=%d, Flag=%d, ModelNumber=%d, Unknown0=%d, Unknown1=%d, Position=%s, Direction=%s",
 		instruction.Index, instruction.Id, instruction.Type, instruction.Flag, instruction.ModelNumber,
 		instruction.Unknown0, instruction.Unknown1,
@@ -289,14 +278,12 @@ func formatDoorModelSetParams(lineBytes []byte) string {
 		formatCoords3D(instruction.Direction[0], instruction.Direction[1], instruction.Direction[2]))
 }
 
-func formatSceEsprControlParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSceEsprControl](lineBytes)
+func formatSceEsprControlParams(instruction ScriptInstrSceEsprControl) string {
 	return fmt.Sprintf("Id=%d, Type=%d, Action=%d, WorkComponent=%d, WorkIndex=%d",
 		instruction.Id, instruction.Type, instruction.Action, instruction.WorkComponent, instruction.WorkIndex)
 }
 
-func formatSceEspr3DOnParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrSceEspr3DOn](lineBytes)
+func formatSceEspr3DOnParams(instruction ScriptInstrSceEspr3DOn) string {
 	return fmt.Sprintf("Dummy=%d, Unknown0=%d, Work=%d, Unknown1=%d, Vector1=%s, Vector2=%s, DirY=%d",
 		instruction.Dummy, instruction.Unknown0, instruction.Work, instruction.Unknown1,
 		formatCoords3D(instruction.Vector1[0], instruction.Vector1[1], instruction.Vector1[2]),
@@ -304,228 +291,219 @@ func formatSceEspr3DOnParams(lineBytes []byte) string {
 		instruction.DirY)
 }
 
-func formatPlcRotParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrPlcRot](lineBytes)
+func formatPlcRotParams(instruction ScriptInstrPlcRot) string {
 	return fmt.Sprintf("Index=%d, Value=%d",
 		instruction.Index, instruction.Value)
 }
 
-func formatXaOnParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrXaOn](lineBytes)
-	return fmt.Sprintf("Channel=%d, Id=%d",
-		instruction.Channel, instruction.Id)
+func formatXaOnParams(instruction ScriptInstrXaOn) string {
+	return fmt.Sprintf("Channel=%d, Id=%d%s",
+		instruction.Channel, instruction.Id, formatSymbolComment(Symbols.XaName(int(instruction.Id))))
 }
 
-func formatMizuDivSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrMizuDivSet](lineBytes)
+func formatMizuDivSetParams(instruction ScriptInstrMizuDivSet) string {
 	return fmt.Sprintf("MizuDivMax=%d", instruction.MizuDivMax)
 }
 
-func formatKageSetParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrKageSet](lineBytes)
+func formatKeepItemCkParams(instruction ScriptInstrKeepItemCk) string {
+	return fmt.Sprintf("ItemId=%d%s", instruction.ItemId, formatItemComment(int(instruction.ItemId)))
+}
+
+func formatKageSetParams(instruction ScriptInstrKageSet) string {
 	return fmt.Sprintf("WorkSetComponent=%d, WorkSetIndex=%d, Color=%s, HalfX=%d, HalfZ=%d, OffsetX=%d, OffsetZ=%d",
 		instruction.WorkSetComponent, instruction.WorkSetIndex,
 		formatArray(instruction.Color[:]), instruction.HalfX, instruction.HalfZ, instruction.OffsetX, instruction.OffsetZ)
 }
 
-func formatDoorAotSet4pParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrDoorAotSet4p](lineBytes)
-	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X1=%d, Z1=%d, X2=%d, Z2=%d, X3=%d, Z3=%d, X4=%d, Z4=%d, NextX=%d, NextY=%d, NextZ=%d, NextDir=%d, Stage=%d, Room=%d, Camera=%d, NextFloor=%d, TextureType=%d, DoorType=%d, KnockType=%d, KeyId=%d, KeyType=%d, Free=%d",
+func formatDoorAotSet4pParams(instruction ScriptInstrDoorAotSet4p) string {
+	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X1=%d, Z1=%d, X2=%d, Z2=%d, X3=%d, Z3=%d, X4=%d, Z4=%d, NextX=%d, NextY=%d, NextZ=%d, NextDir=%d, Stage=%d%s, Room=%d%s, Camera=%d, NextFloor=%d, TextureType=%d, DoorType=%d, KnockType=%d, KeyId=%d%s, KeyType=%d, Free=%d",
 		instruction.Aot, instruction.Id, instruction.Type, instruction.Floor, instruction.Super,
 		instruction.X1, instruction.Z1, instruction.X2, instruction.Z2,
 		instruction.X3, instruction.Z3, instruction.X4, instruction.Z4,
 		instruction.NextX, instruction.NextY, instruction.NextZ, instruction.NextDir,
-		instruction.Stage, instruction.Room, instruction.Camera, instruction.NextFloor,
-		instruction.TextureType, instruction.DoorType, instruction.KnockType,
-		instruction.KeyId, instruction.KeyType, instruction.Free)
+		instruction.Stage, formatStageComment(instruction.Stage),
+		instruction.Room, formatRoomComment(instruction.Stage, instruction.Room),
+		instruction.Camera, instruction.NextFloor, instruction.TextureType, instruction.DoorType, instruction.KnockType,
+		instruction.KeyId, formatItemComment(int(instruction.KeyId)), instruction.KeyType, instruction.Free)
 }
 
-func formatItemAotSet4pParams(lineBytes []byte) string {
-	instruction := readInstruction[ScriptInstrItemAotSet4p](lineBytes)
-	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X1=%d, Z1=%d, X2=%d, Z2=%d, X3=%d, Z3=%d, X4=%d, Z4=%d, ItemId=%d, Amount=%d, ItemPickedIndex=%d, Md1ModelId=%d, Act=%d",
+func formatItemAotSet4pParams(instruction ScriptInstrItemAotSet4p) string {
+	return fmt.Sprintf("Aot=%d, Id=%d, Type=%d, Floor=%d, Super=%d, X1=%d, Z1=%d, X2=%d, Z2=%d, X3=%d, Z3=%d, X4=%d, Z4=%d, ItemId=%d%s, Amount=%d, ItemPickedIndex=%d, Md1ModelId=%d, Act=%d",
 		instruction.Aot, instruction.Id, instruction.Type, instruction.Floor, instruction.Super,
 		instruction.X1, instruction.Z1, instruction.X2, instruction.Z2,
 		instruction.X3, instruction.Z3, instruction.X4, instruction.Z4,
-		instruction.ItemId, instruction.Amount, instruction.ItemPickedIndex, instruction.Md1ModelId, instruction.Act)
-}
-
-// Placeholder signature generators for missing opcodes (based on Rust documentation)
-func formatEvtEndParams(lineBytes []byte) string {
-	return ""
-}
-
-func formatEvtNextParams(lineBytes []byte) string {
-	return ""
+		instruction.ItemId, formatItemComment(int(instruction.ItemId)),
+		instruction.Amount, instruction.ItemPickedIndex, instruction.Md1ModelId, instruction.Act)
 }
 
-func formatEvtChainParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d", lineBytes[1], lineBytes[2], lineBytes[3])
+func formatEventChainParams(instruction ScriptInstrEventChain) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatEvtKillParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatEventKillParams(instruction ScriptInstrEventKill) string {
+	return fmt.Sprintf("Event=%d", instruction.Event)
 }
 
-func formatEndIfParams(lineBytes []byte) string {
-	return ""
+func formatSleepingParams(instruction ScriptInstrSleeping) string {
+	return fmt.Sprintf("Count=%d", instruction.Count)
 }
 
-func formatSleepingParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d", lineBytes[1], lineBytes[2])
+func formatWhileStartParams(instruction ScriptInstrWhileStart) string {
+	return fmt.Sprintf("Dummy=%d, BlockLength=%d",
+		instruction.Dummy, instruction.BlockLength)
 }
 
-func formatWsleepParams(lineBytes []byte) string {
-	return ""
+func formatDoStartParams(instruction ScriptInstrDoStart) string {
+	return fmt.Sprintf("Dummy=%d, BlockLength=%d",
+		instruction.Dummy, instruction.BlockLength)
 }
 
-func formatWsleepingParams(lineBytes []byte) string {
-	return ""
+// dummyInstruction is the struct of the instructions that only have a dummy byte after the opcode
+type dummyInstruction = struct {
+	Opcode uint8
+	Dummy  uint8
 }
 
-func formatForEndParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+// formatDummyParams formats the instructions that only have a dummy byte after the opcode
+func formatDummyParams[T ~dummyInstruction](instruction T) string {
+	return fmt.Sprintf("Dummy=%d", dummyInstruction(instruction).Dummy)
 }
 
-func formatWhileStartParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d", lineBytes[1], binary.LittleEndian.Uint16(lineBytes[2:4]))
+func formatWorkCopyParams(instruction ScriptInstrWorkCopy) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatWhileEndParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatMessageOnParams(instruction ScriptInstrMessageOn) string {
+	return fmt.Sprintf("Dummy=%d, Id=%d, Unknown0=%d, Unknown1=%d",
+		instruction.Dummy, instruction.Id, instruction.Unknown0, instruction.Unknown1)
 }
 
-func formatDoStartParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d", lineBytes[1], binary.LittleEndian.Uint16(lineBytes[2:4]))
+func formatSpeedSetParams(instruction ScriptInstrSpeedSet) string {
+	return fmt.Sprintf("Id=%d, Value=%d", instruction.Id, instruction.Value)
 }
 
-func formatDoEndParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatDirSetParams(instruction ScriptInstrDirSet) string {
+	return fmt.Sprintf("Dummy=%d, X=%d, Y=%d, Z=%d",
+		instruction.Dummy, instruction.X, instruction.Y, instruction.Z)
 }
 
-func formatEndSwitchParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatMemberSet2Params(instruction ScriptInstrMemberSet2) string {
+	return fmt.Sprintf("MemberIndex=%d, VarId=%d", instruction.MemberIndex, instruction.VarId)
 }
 
-func formatDefaultCaseParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d", lineBytes[1], binary.LittleEndian.Uint16(lineBytes[2:4]), binary.LittleEndian.Uint16(lineBytes[4:6]))
+func formatSeOnParams(instruction ScriptInstrSeOn) string {
+	return fmt.Sprintf("VabId=%d, Edt=%d, Data=%d, X=%d, Y=%d, Z=%d",
+		instruction.VabId, instruction.Edt, instruction.Data, instruction.X, instruction.Y, instruction.Z)
 }
 
-func formatGosubReturnParams(lineBytes []byte) string {
-	return ""
+func formatDirCkParams(instruction ScriptInstrDirCk) string {
+	return fmt.Sprintf("Dummy=%d, X=%d, Z=%d, Add=%d",
+		instruction.Dummy, instruction.X, instruction.Z, instruction.Add)
 }
 
-func formatBreakParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatMemberCopyParams(instruction ScriptInstrMemberCopy) string {
+	return fmt.Sprintf("VarId=%d, MemberIndex=%d", instruction.VarId, instruction.MemberIndex)
 }
 
-func formatWorkCopyParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d", lineBytes[1], lineBytes[2], lineBytes[3])
+func formatAotOnParams(instruction ScriptInstrAotOn) string {
+	return fmt.Sprintf("Aot=%d", instruction.Aot)
 }
 
-func formatSceRndParams(lineBytes []byte) string {
-	return ""
+func formatSuperSetParams(instruction ScriptInstrSuperSet) string {
+	return fmt.Sprintf("Dummy=%d, Work=%d, Id=%d, Position=%s, Direction=%s",
+		instruction.Dummy, instruction.Work, instruction.Id,
+		formatCoords3D(instruction.Position[0], instruction.Position[1], instruction.Position[2]),
+		formatCoords3D(instruction.Direction[0], instruction.Direction[1], instruction.Direction[2]))
 }
 
-func formatCutOldParams(lineBytes []byte) string {
-	return ""
+func formatCutReplaceParams(instruction ScriptInstrCutReplace) string {
+	return fmt.Sprintf("FromCameraId=%d, ToCameraId=%d",
+		instruction.FromCameraId, instruction.ToCameraId)
 }
 
-func formatMessageOnParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5])
+func formatSceTrgCkParams(instruction ScriptInstrSceTrgCk) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatSpeedSetParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d", lineBytes[1], lineBytes[2], lineBytes[3])
+func formatSceFadeSetParams(instruction ScriptInstrSceFadeSet) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatAddSpeedParams(lineBytes []byte) string {
-	return ""
+func formatSceBgmTblSetParams(instruction ScriptInstrSceBgmTblSet) string {
+	return fmt.Sprintf("Dummy=%d, Room=%d%s, Stage=%d%s, Data0=%d%s, Data1=%d%s",
+		instruction.Dummy, instruction.Room, formatRoomComment(instruction.Stage, instruction.Room),
+		instruction.Stage, formatStageComment(instruction.Stage),
+		instruction.Data0, formatSymbolComment(Symbols.BgmName(int(instruction.Data0))),
+		instruction.Data1, formatSymbolComment(Symbols.BgmName(int(instruction.Data1))))
 }
 
-func formatAddAspeedParams(lineBytes []byte) string {
-	return ""
+func formatWeaponChgParams(instruction ScriptInstrWeaponChg) string {
+	return fmt.Sprintf("WeaponId=%d", instruction.WeaponId)
 }
 
-func formatDirSetParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d, param6=%d, param7=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5], lineBytes[6], lineBytes[7])
+func formatPlcCntParams(instruction ScriptInstrPlcCnt) string {
+	return fmt.Sprintf("Count=%d", instruction.Count)
 }
 
-func formatMemberSet2Params(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d", lineBytes[1], lineBytes[2])
+func formatSceShakeOnParams(instruction ScriptInstrSceShakeOn) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatSeOnParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d, param6=%d, param7=%d, param8=%d, param9=%d, param10=%d, param11=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5], lineBytes[6], lineBytes[7], lineBytes[8], lineBytes[9], lineBytes[10], lineBytes[11])
+func formatXaVolParams(instruction ScriptInstrXaVol) string {
+	return fmt.Sprintf("Volume=%d", instruction.Volume)
 }
 
-func formatDirCkParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d, param6=%d, param7=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5], lineBytes[6], lineBytes[7])
+func formatCutBeSetParams(instruction ScriptInstrCutBeSet) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatMemberCopyParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d", lineBytes[1], lineBytes[2])
+func formatSceItemLostParams(instruction ScriptInstrSceItemLost) string {
+	return fmt.Sprintf("ItemId=%d%s", instruction.ItemId, formatItemComment(int(instruction.ItemId)))
 }
 
-func formatPlcRetParams(lineBytes []byte) string {
-	return ""
+func formatSceEsprOn2Params(instruction ScriptInstrSceEsprOn2) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatAotOnParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatSceEsprKill2Params(instruction ScriptInstrSceEsprKill2) string {
+	return fmt.Sprintf("Id=%d", instruction.Id)
 }
 
-func formatCutReplaceParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d", lineBytes[1], lineBytes[2])
+func formatLightPosSetParams(instruction ScriptInstrLightPosSet) string {
+	return fmt.Sprintf("Dummy=%d, Index=%d, Axis=%d, Value=%d",
+		instruction.Dummy, instruction.Index, instruction.Axis, instruction.Value)
 }
 
-func formatSceBgmtblSetParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d, param6=%d, param7=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5], lineBytes[6], lineBytes[7])
+func formatLightKidoSetParams(instruction ScriptInstrLightKidoSet) string {
+	return fmt.Sprintf("Index=%d, Luminosity=%d", instruction.Index, instruction.Luminosity)
 }
 
-func formatPlcCntParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatSceScrMoveParams(instruction ScriptInstrSceScrMove) string {
+	return fmt.Sprintf("Dummy=%d, Value=%d", instruction.Dummy, instruction.Value)
 }
 
-func formatXaVolParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatPartsSetParams(instruction ScriptInstrPartsSet) string {
+	return fmt.Sprintf("Dummy=%d, Id=%d, Type=%d, Value=%d",
+		instruction.Dummy, instruction.Id, instruction.Type, instruction.Value)
 }
 
-func formatCutBeSetParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d", lineBytes[1], lineBytes[2], lineBytes[3])
+func formatMovieOnParams(instruction ScriptInstrMovieOn) string {
+	return fmt.Sprintf("Id=%d", instruction.Id)
 }
 
-func formatSceItemLostParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d", lineBytes[1])
+func formatScePartsBombParams(instruction ScriptInstrScePartsBomb) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatSceEsprOn2Params(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d, param6=%d, param7=%d, param8=%d, param9=%d, param10=%d, param11=%d, param12=%d, param13=%d, param14=%d, param15=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5], lineBytes[6], lineBytes[7], lineBytes[8], lineBytes[9], lineBytes[10], lineBytes[11], lineBytes[12], lineBytes[13], lineBytes[14], lineBytes[15])
+func formatScePartsDownParams(instruction ScriptInstrScePartsDown) string {
+	return fmt.Sprintf("Data=%s", formatArray(instruction.Data[:]))
 }
 
-func formatPlcStopParams(lineBytes []byte) string {
+// formatNoParams formats the instructions that only consist of the opcode
+func formatNoParams(instruction Instruction) string {
 	return ""
 }
 
-func formatLightPosSetParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5])
-}
-
-func formatLightKidoSetParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d", lineBytes[1], lineBytes[2], lineBytes[3])
-}
-
-func formatPartsSetParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5])
-}
-
-func formatScePartsBombParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d, param6=%d, param7=%d, param8=%d, param9=%d, param10=%d, param11=%d, param12=%d, param13=%d, param14=%d, param15=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5], lineBytes[6], lineBytes[7], lineBytes[8], lineBytes[9], lineBytes[10], lineBytes[11], lineBytes[12], lineBytes[13], lineBytes[14], lineBytes[15])
-}
-
-func formatScePartsDownParams(lineBytes []byte) string {
-	return fmt.Sprintf("param1=%d, param2=%d, param3=%d, param4=%d, param5=%d, param6=%d, param7=%d, param8=%d, param9=%d, param10=%d, param11=%d, param12=%d, param13=%d, param14=%d, param15=%d", lineBytes[1], lineBytes[2], lineBytes[3], lineBytes[4], lineBytes[5], lineBytes[6], lineBytes[7], lineBytes[8], lineBytes[9], lineBytes[10], lineBytes[11], lineBytes[12], lineBytes[13], lineBytes[14], lineBytes[15])
-}
-
 func formatDefaultParams(lineBytes []byte) string {
 	var params []string
 	for i := 1; i < len(lineBytes); i++ {
@@ -534,144 +512,243 @@ func formatDefaultParams(lineBytes []byte) string {
 	return strings.Join(params, ", ")
 }
 
-// Map of opcodes to their signature generators
+// Map of the RE2 opcodes to their signature generators
 var OpcodeSignatures = map[byte]OpcodeSignature{
 	// Control flow opcodes
-	OP_EVT_EXEC:   formatEventExecParams,
-	OP_IF_START:   formatIfElseStartParams,
-	OP_ELSE_START: formatElseStartParams,
-	OP_SLEEP:      formatSleepParams,
-	OP_FOR:        formatForStartParams,
-	OP_SWITCH:     formatSwitchParams,
-	OP_CASE:       formatSwitchCaseParams,
-	OP_GOTO:       formatGotoParams,
-	OP_GOSUB:      formatGosubParams,
+	OP_EVT_EXEC:   signatureOf(formatEventExecParams),
+	OP_IF_START:   signatureOf(formatIfElseStartParams),
+	OP_ELSE_START: signatureOf(formatElseStartParams),
+	OP_SLEEP:      signatureOf(formatSleepParams),
+	OP_FOR:        signatureOf(formatForStartParams),
+	OP_SWITCH:     signatureOf(formatSwitchParams),
+	OP_CASE:       signatureOf(formatSwitchCaseParams),
+	OP_GOTO:       signatureOf(formatGotoParams),
+	OP_GOSUB:      signatureOf(formatGosubParams),
 
 	// Data manipulation opcodes
-	OP_CHECK:   formatCheckBitParams,
-	OP_SET_BIT: formatSetBitParams,
-	OP_COMPARE: formatCompareParams,
-	OP_SAVE:    formatSaveParams,
-	OP_COPY:    formatCopyParams,
-	OP_CALC:    formatCalcParams,
-	OP_CALC2:   formatCalc2Params,
+	OP_CHECK:   signatureOf(formatCheckBitParams),
+	OP_SET_BIT: signatureOf(formatSetBitParams),
+	OP_COMPARE: signatureOf(formatCompareParams),
+	OP_SAVE:    signatureOf(formatSaveParams),
+	OP_COPY:    signatureOf(formatCopyParams),
+	OP_CALC:    signatureOf(formatCalcParams),
+	OP_CALC2:   signatureOf(formatCalc2Params),
 
 	// Scene and camera opcodes
-	OP_CUT_CHG:  formatCutChgParams,
-	OP_CUT_AUTO: formatCutAutoParams,
+	OP_CUT_CHG:  signatureOf(formatCutChgParams),
+	OP_CUT_AUTO: signatureOf(formatCutAutoParams),
 
 	// Area of Trigger (AOT) opcodes
-	OP_AOT_SET:    formatAotSetParams,
-	OP_AOT_RESET:  formatAotResetParams,
-	OP_AOT_SET_4P: formatAotSet4pParams,
+	OP_AOT_SET:    signatureOf(formatAotSetParams),
+	OP_AOT_RESET:  signatureOf(formatAotResetParams),
+	OP_AOT_SET_4P: signatureOf(formatAotSet4pParams),
 
 	// Object and model opcodes
-	OP_OBJ_MODEL_SET:  formatObjModelSetParams,
-	OP_DOOR_MODEL_SET: formatDoorModelSetParams,
+	OP_OBJ_MODEL_SET:  signatureOf(formatObjModelSetParams),
+	OP_DOOR_MODEL_SET: signatureOf(formatDoorModelSetParams),
 
 	// Work and member opcodes
-	OP_WORK_SET:   formatWorkSetParams,
-	OP_MEMBER_SET: formatMemberSetParams,
-	OP_MEMBER_CMP: formatMemberCompareParams,
+	OP_WORK_SET:   signatureOf(formatWorkSetParams),
+	OP_MEMBER_SET: signatureOf(formatMemberSetParams),
+	OP_MEMBER_CMP: signatureOf(formatMemberCompareParams),
 
 	// Position and movement opcodes
-	OP_POS_SET: formatPosSetParams,
+	OP_POS_SET: signatureOf(formatPosSetParams),
 
 	// Scene ID opcodes
-	OP_SCA_ID_SET: formatScaIdSetParams,
+	OP_SCA_ID_SET: signatureOf(formatScaIdSetParams),
 
 	// Effect and sprite opcodes
-	OP_SCE_ESPR_ON:      formatSceEsprOnParams,
-	OP_SCE_ESPR_KILL:    formatSceEsprKillParams,
-	OP_SCE_ESPR_CONTROL: formatSceEsprControlParams,
-	OP_SCE_ESPR3D_ON:    formatSceEspr3DOnParams,
+	OP_SCE_ESPR_ON:      signatureOf(formatSceEsprOnParams),
+	OP_SCE_ESPR_KILL:    signatureOf(formatSceEsprKillParams),
+	OP_SCE_ESPR_CONTROL: signatureOf(formatSceEsprControlParams),
+	OP_SCE_ESPR3D_ON:    signatureOf(formatSceEspr3DOnParams),
 
 	// Door opcodes
-	OP_DOOR_AOT_SET:    formatDoorAotSetParams,
-	OP_DOOR_AOT_SET_4P: formatDoorAotSet4pParams,
+	OP_DOOR_AOT_SET:    signatureOf(formatDoorAotSetParams),
+	OP_DOOR_AOT_SET_4P: signatureOf(formatDoorAotSet4pParams),
 
 	// Player control opcodes
-	OP_PLC_MOTION: formatPlcMotionParams,
-	OP_PLC_DEST:   formatPlcDestParams,
-	OP_PLC_NECK:   formatPlcNeckParams,
-	OP_PLC_FLAG:   formatPlcFlagParams,
-	OP_PLC_ROT:    formatPlcRotParams,
+	OP_PLC_MOTION: signatureOf(formatPlcMotionParams),
+	OP_PLC_DEST:   signatureOf(formatPlcDestParams),
+	OP_PLC_NECK:   signatureOf(formatPlcNeckParams),
+	OP_PLC_FLAG:   signatureOf(formatPlcFlagParams),
+	OP_PLC_ROT:    signatureOf(formatPlcRotParams),
 
 	// Entity management opcodes
-	OP_SCE_EM_SET: formatSceEmSetParams,
+	OP_SCE_EM_SET: signatureOf(formatSceEmSetParams),
 
 	// Item opcodes
-	OP_ITEM_AOT_SET:    formatItemAotSetParams,
-	OP_ITEM_AOT_SET_4P: formatItemAotSet4pParams,
+	OP_ITEM_AOT_SET:    signatureOf(formatItemAotSetParams),
+	OP_ITEM_AOT_SET_4P: signatureOf(formatItemAotSet4pParams),
+	OP_KEEP_ITEM_CK:    signatureOf(formatKeepItemCkParams),
 
 	// Audio opcodes
-	OP_SCE_BGM_CONTROL: formatSceBgmControlParams,
-	OP_XA_ON:           formatXaOnParams,
+	OP_SCE_BGM_CONTROL: signatureOf(formatSceBgmControlParams),
+	OP_XA_ON:           signatureOf(formatXaOnParams),
 
 	// Visual effects opcodes
-	OP_KAGE_SET:     formatKageSetParams,
-	OP_MIZU_DIV_SET: formatMizuDivSetParams,
-
-	// Additional placeholder formatters for complete coverage
-	OP_EVT_END:        formatEvtEndParams,
-	OP_EVT_NEXT:       formatEvtNextParams,
-	OP_EVT_CHAIN:      formatEvtChainParams,
-	OP_EVT_KILL:       formatEvtKillParams,
-	OP_END_IF:         formatEndIfParams,
-	OP_SLEEPING:       formatSleepingParams,
-	OP_WSLEEP:         formatWsleepParams,
-	OP_WSLEEPING:      formatWsleepingParams,
-	OP_FOR_END:        formatForEndParams,
-	OP_WHILE_START:    formatWhileStartParams,
-	OP_WHILE_END:      formatWhileEndParams,
-	OP_DO_START:       formatDoStartParams,
-	OP_DO_END:         formatDoEndParams,
-	OP_END_SWITCH:     formatEndSwitchParams,
-	OP_DEFAULT:        formatDefaultCaseParams,
-	OP_GOSUB_RETURN:   formatGosubReturnParams,
-	OP_BREAK:          formatBreakParams,
-	OP_WORK_COPY:      formatWorkCopyParams,
-	OP_SCE_RND:        formatSceRndParams,
-	OP_CUT_OLD:        formatCutOldParams,
-	OP_MESSAGE_ON:     formatMessageOnParams,
-	OP_SPEED_SET:      formatSpeedSetParams,
-	OP_ADD_SPEED:      formatAddSpeedParams,
-	OP_ADD_ASPEED:     formatAddAspeedParams,
-	OP_DIR_SET:        formatDirSetParams,
-	OP_MEMBER_SET2:    formatMemberSet2Params,
-	OP_SE_ON:          formatSeOnParams,
-	OP_DIR_CK:         formatDirCkParams,
-	OP_MEMBER_COPY:    formatMemberCopyParams,
-	OP_PLC_RET:        formatPlcRetParams,
-	OP_AOT_ON:         formatAotOnParams,
-	OP_CUT_REPLACE:    formatCutReplaceParams,
-	OP_SCE_BGMTBL_SET: formatSceBgmtblSetParams,
-	OP_PLC_CNT:        formatPlcCntParams,
-	OP_XA_VOL:         formatXaVolParams,
-	OP_CUT_BE_SET:     formatCutBeSetParams,
-	OP_KEEP_ITEM_CK:   formatSceItemLostParams,
-	OP_SCE_ITEM_LOST:  formatSceItemLostParams,
-	OP_SCE_ESPR_ON2:   formatSceEsprOn2Params,
-	OP_SCE_ESPR_KILL2: formatSceEsprOn2Params,
-	OP_PLC_STOP:       formatPlcStopParams,
-	OP_LIGHT_POS_SET:  formatLightPosSetParams,
-	OP_LIGHT_KIDO_SET: formatLightKidoSetParams,
-	OP_RBJ_RESET:      formatPartsSetParams,
-	OP_SCE_SCR_MOVE:   formatPartsSetParams,
-	OP_PARTS_SET:      formatPartsSetParams,
-	OP_MOVIE_ON:       formatPartsSetParams,
-	OP_SCE_PARTS_BOMB: formatScePartsBombParams,
-	OP_SCE_PARTS_DOWN: formatScePartsDownParams,
-}
-
-// GetOpcodeSignature converts binary instruction data to IntelliSense-like function signature
-func GetOpcodeSignature(lineBytes []byte) string {
-	opcode := lineBytes[0]
-
-	signature, exists := OpcodeSignatures[opcode]
+	OP_KAGE_SET:     signatureOf(formatKageSetParams),
+	OP_MIZU_DIV_SET: signatureOf(formatMizuDivSetParams),
+
+	// Instructions without parameters
+	OP_NO_OP:       formatNoParams,
+	OP_EVT_END:     formatNoParams,
+	OP_EVT_NEXT:    formatNoParams,
+	OP_END_IF:      formatNoParams,
+	OP_WSLEEP:      formatNoParams,
+	OP_WSLEEPING:   formatNoParams,
+	OP_NO_OP2:      formatNoParams,
+	OP_SCE_RND:     formatNoParams,
+	OP_CUT_OLD:     formatNoParams,
+	OP_ADD_SPEED:   formatNoParams,
+	OP_ADD_ASPEED:  formatNoParams,
+	OP_PLC_RET:     formatNoParams,
+	OP_PLC_GUN_EFF: formatNoParams,
+	OP_PLC_STOP:    formatNoParams,
+	OP_RBJ_RESET:   formatNoParams,
+
+	// Instructions with a single dummy byte
+	OP_FOR_END:      signatureOf(formatDummyParams[ScriptInstrForEnd]),
+	OP_WHILE_END:    signatureOf(formatDummyParams[ScriptInstrWhileEnd]),
+	OP_DO_END:       signatureOf(formatDummyParams[ScriptInstrDoEnd]),
+	OP_DEFAULT:      signatureOf(formatDummyParams[ScriptInstrDefault]),
+	OP_END_SWITCH:   signatureOf(formatDummyParams[ScriptInstrEndSwitch]),
+	OP_GOSUB_RETURN: signatureOf(formatDummyParams[ScriptInstrGoSubReturn]),
+	OP_BREAK:        signatureOf(formatDummyParams[ScriptInstrBreak]),
+
+	// Remaining opcodes
+	OP_EVT_CHAIN:      signatureOf(formatEventChainParams),
+	OP_EVT_KILL:       signatureOf(formatEventKillParams),
+	OP_SLEEPING:       signatureOf(formatSleepingParams),
+	OP_WHILE_START:    signatureOf(formatWhileStartParams),
+	OP_DO_START:       signatureOf(formatDoStartParams),
+	OP_WORK_COPY:      signatureOf(formatWorkCopyParams),
+	OP_MESSAGE_ON:     signatureOf(formatMessageOnParams),
+	OP_SPEED_SET:      signatureOf(formatSpeedSetParams),
+	OP_DIR_SET:        signatureOf(formatDirSetParams),
+	OP_MEMBER_SET2:    signatureOf(formatMemberSet2Params),
+	OP_SE_ON:          signatureOf(formatSeOnParams),
+	OP_DIR_CK:         signatureOf(formatDirCkParams),
+	OP_MEMBER_COPY:    signatureOf(formatMemberCopyParams),
+	OP_AOT_ON:         signatureOf(formatAotOnParams),
+	OP_SUPER_SET:      signatureOf(formatSuperSetParams),
+	OP_CUT_REPLACE:    signatureOf(formatCutReplaceParams),
+	OP_SCE_TRG_CK:     signatureOf(formatSceTrgCkParams),
+	OP_SCE_FADE_SET:   signatureOf(formatSceFadeSetParams),
+	OP_SCE_BGMTBL_SET: signatureOf(formatSceBgmTblSetParams),
+	OP_WEAPON_CHG:     signatureOf(formatWeaponChgParams),
+	OP_PLC_CNT:        signatureOf(formatPlcCntParams),
+	OP_SCE_SHAKE_ON:   signatureOf(formatSceShakeOnParams),
+	OP_XA_VOL:         signatureOf(formatXaVolParams),
+	OP_CUT_BE_SET:     signatureOf(formatCutBeSetParams),
+	OP_SCE_ITEM_LOST:  signatureOf(formatSceItemLostParams),
+	OP_SCE_ESPR_ON2:   signatureOf(formatSceEsprOn2Params),
+	OP_SCE_ESPR_KILL2: signatureOf(formatSceEsprKill2Params),
+	OP_LIGHT_POS_SET:  signatureOf(formatLightPosSetParams),
+	OP_LIGHT_KIDO_SET: signatureOf(formatLightKidoSetParams),
+	OP_SCE_SCR_MOVE:   signatureOf(formatSceScrMoveParams),
+	OP_PARTS_SET:      signatureOf(formatPartsSetParams),
+	OP_MOVIE_ON:       signatureOf(formatMovieOnParams),
+	OP_SCE_PARTS_BOMB: signatureOf(formatScePartsBombParams),
+	OP_SCE_PARTS_DOWN: signatureOf(formatScePartsDownParams),
+}
+
+// Room opcode signature function type, used for parameters that refer to other data in the room
+type RoomOpcodeSignature func(Instruction, *RDTOutput) string
+
+// roomSignatureOf is like signatureOf for the signatures that show data of the room
+func roomSignatureOf[T any](format func(T, *RDTOutput) string) RoomOpcodeSignature {
+	return func(instruction Instruction, rdtOutput *RDTOutput) string {
+		decoded, ok := instruction.Decoded().(T)
+		if !ok {
+			return formatDefaultParams(instruction.Bytes())
+		}
+		return format(decoded, rdtOutput)
+	}
+}
+
+// maxMessagePreviewLength is the number of characters of a message shown next to MessageOn
+const maxMessagePreviewLength = 60
+
+func formatMessageOnRoomParams(instruction ScriptInstrMessageOn, rdtOutput *RDTOutput) string {
+	return fmt.Sprintf("Dummy=%d, Id=%d%s, Unknown0=%d, Unknown1=%d",
+		instruction.Dummy, instruction.Id, formatMessagePreview(rdtOutput, int(instruction.Id)),
+		instruction.Unknown0, instruction.Unknown1)
+}
+
+// formatMessagePreview returns the beginning of the message as a comment.
+// The first language block whose text is decoded and contains the message is used.
+func formatMessagePreview(rdtOutput *RDTOutput, id int) string {
+	for _, msgOutput := range []*MSGOutput{rdtOutput.Lang1MessageData, rdtOutput.Lang2MessageData} {
+		if msgOutput == nil || !msgOutput.Decoded || id >= len(msgOutput.Messages) {
+			continue
+		}
+
+		preview := []rune(strings.ReplaceAll(msgOutput.Messages[id], "\n", " "))
+		if len(preview) > maxMessagePreviewLength {
+			preview = append(preview[:maxMessagePreviewLength], '…')
+		}
+		return fmt.Sprintf(" /* %q */", string(preview))
+	}
+	return ""
+}
+
+func formatCutChgRoomParams(instruction ScriptInstrCutChg, rdtOutput *RDTOutput) string {
+	return fmt.Sprintf("CameraId=%d%s", instruction.CameraId, formatCameraComment(rdtOutput, int(instruction.CameraId)))
+}
+
+func formatCutReplaceRoomParams(instruction ScriptInstrCutReplace, rdtOutput *RDTOutput) string {
+	return fmt.Sprintf("FromCameraId=%d%s, ToCameraId=%d%s",
+		instruction.FromCameraId, formatCameraComment(rdtOutput, int(instruction.FromCameraId)),
+		instruction.ToCameraId, formatCameraComment(rdtOutput, int(instruction.ToCameraId)))
+}
+
+func formatCutAutoRoomParams(instruction ScriptInstrCutAuto, rdtOutput *RDTOutput) string {
+	if instruction.FlagOn == 0 {
+		return fmt.Sprintf("FlagOn=%d /* camera stays fixed */", instruction.FlagOn)
+	}
+	if rdtOutput.CameraPositionData == nil {
+		return fmt.Sprintf("FlagOn=%d /* camera switches automatically */", instruction.FlagOn)
+	}
+	return fmt.Sprintf("FlagOn=%d /* camera switches automatically between %d cameras */",
+		instruction.FlagOn, len(rdtOutput.CameraPositionData.Cameras))
+}
+
+// formatCameraComment describes the camera position as a comment
+func formatCameraComment(rdtOutput *RDTOutput, cameraId int) string {
+	if rdtOutput.CameraPositionData == nil || cameraId >= len(rdtOutput.CameraPositionData.Cameras) {
+		return " /* unknown camera */"
+	}
+	return " /* " + rdtOutput.CameraPositionData.Cameras[cameraId].Describe() + " */"
+}
+
+// Map of opcodes to signature generators that show data from the rest of the room
+var RoomOpcodeSignatures = map[byte]RoomOpcodeSignature{
+	OP_MESSAGE_ON:  roomSignatureOf(formatMessageOnRoomParams),
+	OP_CUT_CHG:     roomSignatureOf(formatCutChgRoomParams),
+	OP_CUT_REPLACE: roomSignatureOf(formatCutReplaceRoomParams),
+	OP_CUT_AUTO:    roomSignatureOf(formatCutAutoRoomParams),
+}
+
+// GetRoomOpcodeSignature is like GetOpcodeSignature, but also shows the room data
+// that the parameters refer to, e.g. the text of a message
+func GetRoomOpcodeSignature(instruction Instruction, rdtOutput *RDTOutput) string {
+	signature, exists := instruction.opcodeSet().roomSignatures[instruction.Bytes()[0]]
+	if !exists || rdtOutput == nil {
+		return GetOpcodeSignature(instruction)
+	}
+
+	return "(" + signature(instruction, rdtOutput) + ");"
+}
+
+// GetOpcodeSignature converts a decoded instruction to IntelliSense-like function signature,
+// using the signatures of the opcodes it was decoded with
+func GetOpcodeSignature(instruction Instruction) string {
+	signature, exists := instruction.opcodeSet().signatures[instruction.Bytes()[0]]
 	if !exists {
-		return "(" + formatDefaultParams(lineBytes) + ");"
+		return "(" + formatDefaultParams(instruction.Bytes()) + ");"
 	}
 
-	return "(" + signature(lineBytes) + ");"
+	return "(" + signature(instruction) + ");"
 }
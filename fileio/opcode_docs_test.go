@@ -0,0 +1,22 @@
+package fileio
+
+import "testing"
+
+func TestConvertScriptInstructionsToCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		lineBytes []byte
+		want      string
+	}{
+		{"struct fields", []byte{OP_GOSUB, 3}, "Gosub(Event=3);\n"},
+		{"dummy byte", []byte{OP_FOR_END, 7}, "ForEnd(Dummy=7);\n"},
+		{"no parameters", []byte{OP_EVT_END}, "EvtEnd();\n"},
+		{"truncated instruction", []byte{OP_CUT_REPLACE, 5}, "CutReplace(5);\n"},
+		{"unknown opcode", []byte{0xf0, 1, 2}, "(1, 2);\n"},
+	}
+	for _, test := range tests {
+		if got := ConvertScriptInstructionsToCode([][]byte{test.lineBytes}, nil); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package fileio
+
+// Opcode tables of a game. Every room keeps the opcodes of its game, so rooms of
+// different games can be parsed, printed and run side by side.
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// OpcodeSet holds the opcodes of a game: the built-in RE2 tables or the embedded spec of the
+// game, with the loaded spec files for the game applied on top
+type OpcodeSet struct {
+	Game *Game
+
+	sizes          map[byte]int
+	names          map[byte]string
+	byName         map[string]byte
+	decoders       map[byte]instructionDecoder
+	signatures     map[byte]OpcodeSignature
+	roomSignatures map[byte]RoomOpcodeSignature
+
+	specs     map[byte]OpcodeSpec          // opcodes defined by a spec
+	enums     map[string]map[string]string // value names of the specs
+	canonical map[byte]byte                // opcodes whose meaning differs from the RE2 opcode with the same number, see CanonicalOpcode
+	resized   map[byte]int                 // opcodes that a spec gives another size than the RE2 opcode with the same number, mapped to the RE2 size
+}
+
+// newOpcodeSet builds the opcodes of a game from the RE2 tables and the specs of the game
+func newOpcodeSet(game *Game) (*OpcodeSet, error) {
+	spec, err := game.opcodeSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	opcodes := &OpcodeSet{
+		Game:           game,
+		sizes:          maps.Clone(InstructionSize),
+		names:          maps.Clone(FunctionName),
+		byName:         maps.Clone(opcodeByName),
+		decoders:       maps.Clone(instructionDecoders),
+		signatures:     maps.Clone(OpcodeSignatures),
+		roomSignatures: maps.Clone(RoomOpcodeSignatures),
+		specs:          make(map[byte]OpcodeSpec),
+		enums:          make(map[string]map[string]string),
+		canonical:      make(map[byte]byte),
+		resized:        make(map[byte]int),
+	}
+	if !game.re2Opcodes {
+		// The spec of the game replaces all RE2 opcodes
+		clear(opcodes.sizes)
+		clear(opcodes.names)
+		clear(opcodes.byName)
+		clear(opcodes.decoders)
+		clear(opcodes.signatures)
+		clear(opcodes.roomSignatures)
+	}
+	if spec != nil {
+		opcodes.apply(spec)
+	}
+	for _, userSpec := range game.userSpecs() {
+		opcodes.apply(userSpec)
+	}
+	return opcodes, nil
+}
+
+// re2OpcodeSet returns the opcodes of RE2. RE2 has no embedded spec, so they cannot fail to build.
+func re2OpcodeSet() *OpcodeSet {
+	opcodes, _ := GameRE2.Opcodes()
+	return opcodes
+}
+
+// SelectedOpcodes returns the opcodes of the selected game, or of RE2 if the game of every room is detected.
+// They are used for scripts that do not belong to a room, e.g. by the assemble command.
+func SelectedOpcodes() (*OpcodeSet, error) {
+	if SelectedGame == nil {
+		return re2OpcodeSet(), nil
+	}
+	return SelectedGame.Opcodes()
+}
+
+// roomOpcodes returns the opcodes of a room, or the RE2 opcodes if there is no room
+func roomOpcodes(rdtOutput *RDTOutput) *OpcodeSet {
+	if rdtOutput == nil || rdtOutput.Opcodes == nil {
+		return re2OpcodeSet()
+	}
+	return rdtOutput.Opcodes
+}
+
+// InstructionSize returns the size of an opcode in bytes, including the opcode
+func (opcodes *OpcodeSet) InstructionSize(opcode byte) (int, bool) {
+	size, exists := opcodes.sizes[opcode]
+	return size, exists
+}
+
+// FunctionName returns the name of an opcode, or an empty string for an unknown opcode
+func (opcodes *OpcodeSet) FunctionName(opcode byte) string {
+	return opcodes.names[opcode]
+}
+
+// CanonicalOpcode returns the RE2 opcode with the same meaning as an opcode of the game,
+// which is the opcode itself in RE2 rooms, or OP_NO_MEANING
+func (opcodes *OpcodeSet) CanonicalOpcode(opcode byte) byte {
+	if canonical, exists := opcodes.canonical[opcode]; exists {
+		return canonical
+	}
+	return opcode
+}
+
+// HasOpcode tells whether the game has an opcode with the meaning of the RE2 opcode
+func (opcodes *OpcodeSet) HasOpcode(canonical byte) bool {
+	for opcode := range opcodes.sizes {
+		if opcodes.CanonicalOpcode(opcode) == canonical {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeInstruction decodes the bytes of a single instruction located at the program counter
+func (opcodes *OpcodeSet) DecodeInstruction(programCounter int, lineBytes []byte) (Instruction, error) {
+	if len(lineBytes) == 0 {
+		return nil, fmt.Errorf("empty instruction at offset %d", programCounter)
+	}
+
+	decoder, exists := opcodes.decoders[lineBytes[0]]
+	if !exists {
+		return nil, fmt.Errorf("unknown opcode 0x%02x at offset %d", lineBytes[0], programCounter)
+	}
+	decoded, err := decoder(lineBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s at offset %d: %w", opcodes.names[lineBytes[0]], programCounter, err)
+	}
+
+	instruction := &scriptInstruction{
+		opcode:    opcodes.CanonicalOpcode(lineBytes[0]),
+		offset:    programCounter,
+		lineBytes: lineBytes,
+		decoded:   decoded,
+		opcodes:   opcodes,
+	}
+	return instruction, nil
+}
+
+// Definitions returns the definition of every opcode, taken from the specs or from the
+// fields of the ScriptInstr structs, and the enums of the spec files
+func (opcodes *OpcodeSet) Definitions() *OpcodeSpecFile {
+	spec := &OpcodeSpecFile{Opcodes: make([]OpcodeSpec, 0, len(opcodes.sizes)), Enums: opcodes.enums}
+	if opcodes.Game != GameRE2 {
+		spec.Game = opcodes.Game.Id
+		layout := opcodes.Game.roomLayout()
+		spec.Layout = &layout
+	}
+	numbers := make([]int, 0, len(opcodes.sizes))
+	for opcode := range opcodes.sizes {
+		numbers = append(numbers, int(opcode))
+	}
+	sort.Ints(numbers)
+
+	for _, opcode := range numbers {
+		if opcodeSpec, exists := opcodes.specs[byte(opcode)]; exists {
+			spec.Opcodes = append(spec.Opcodes, opcodeSpec)
+			continue
+		}
+		spec.Opcodes = append(spec.Opcodes, builtinOpcodeSpec(byte(opcode)))
+	}
+	return spec
+}
+
+// apply replaces the definitions of the opcodes of a validated spec
+func (opcodes *OpcodeSet) apply(spec *OpcodeSpecFile) {
+	maps.Copy(opcodes.enums, spec.Enums)
+	// Remove the old names first, since opcodes can swap names
+	for _, opcodeSpec := range spec.Opcodes {
+		if oldName, exists := opcodes.names[byte(opcodeSpec.Opcode)]; exists {
+			delete(opcodes.byName, oldName)
+		}
+	}
+	for _, opcodeSpec := range spec.Opcodes {
+		opcode := byte(opcodeSpec.Opcode)
+		opcodes.names[opcode] = opcodeSpec.Name
+		opcodes.byName[opcodeSpec.Name] = opcode
+		opcodes.sizes[opcode] = opcodeSpec.Size
+		opcodes.specs[opcode] = opcodeSpec
+		delete(opcodes.canonical, opcode)
+		delete(opcodes.resized, opcode)
+		delete(opcodes.signatures, opcode)
+		delete(opcodes.roomSignatures, opcode)
+
+		if builtin, exists := opcodeByName[opcodeSpec.Builtin]; exists {
+			// Same meaning as an RE2 opcode
+			opcodes.decoders[opcode] = instructionDecoders[builtin]
+			opcodes.canonical[opcode] = builtin
+			if signature, exists := OpcodeSignatures[builtin]; exists {
+				opcodes.signatures[opcode] = signature
+			}
+			if signature, exists := RoomOpcodeSignatures[builtin]; exists {
+				opcodes.roomSignatures[opcode] = signature
+			}
+		} else if decoder, exists := instructionDecoders[opcode]; exists && opcodes.Game.re2Opcodes && InstructionSize[opcode] == opcodeSpec.Size {
+			// The struct of the opcode is still used by the decompiler and the interpreter if it has the same size
+			opcodes.decoders[opcode] = decoder
+		} else {
+			opcodes.decoders[opcode] = opcodeSpec.decode
+			if !opcodes.Game.re2Opcodes {
+				opcodes.canonical[opcode] = OP_NO_MEANING
+			} else if size, exists := InstructionSize[opcode]; exists && size != opcodeSpec.Size {
+				// The instruction cannot be read as the RE2 opcode with another size
+				opcodes.canonical[opcode] = OP_NO_MEANING
+				opcodes.resized[opcode] = size
+			}
+		}
+		if len(opcodeSpec.Fields) > 0 {
+			opcodes.signatures[opcode] = func(instruction Instruction) string {
+				return opcodeSpec.formatParams(instruction, opcodes.enumNames)
+			}
+		}
+	}
+}
+
+// enumNames returns the names of an enum of the specs or of a table of the symbol table
+func (opcodes *OpcodeSet) enumNames(enum string) (map[string]string, bool) {
+	if names, exists := opcodes.enums[enum]; exists {
+		return names, true
+	}
+	return symbolEnumNames(enum)
+}
@@ -0,0 +1,25 @@
+package fileio
+
+import "testing"
+
+func TestOpcodeSetsOfGamesAreIndependent(t *testing.T) {
+	re3Opcodes, err := GameRE3.Opcodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	instruction, err := re3Opcodes.DecodeInstruction(0, []byte{0x1c})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Using the RE2 opcodes afterwards does not change the instruction of the RE3 room
+	if name := re2OpcodeSet().FunctionName(0x1c); name != "" {
+		t.Errorf("RE2 has opcode 0x1c named %s, want none", name)
+	}
+	if _, err := re2OpcodeSet().DecodeInstruction(0, []byte{0x1c}); err == nil {
+		t.Error("RE2 decodes opcode 0x1c of RE3")
+	}
+	if name := instruction.Name(); name != "BreakPoint" {
+		t.Errorf("RE3 instruction is named %q, want BreakPoint", name)
+	}
+}
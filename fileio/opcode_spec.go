@@ -0,0 +1,435 @@
+package fileio
+
+// Opcode definitions that can be loaded from a spec file instead of the built-in tables.
+// A spec file lists the name, size and fields of some or all opcodes. Opcodes in the file
+// replace the built-in definition of the same opcode in the parser, the signatures and
+// the assembler, so field meanings can be corrected and variant builds of the game can
+// be read without recompiling.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// OpcodeSpecFile is the content of an opcode spec file
+type OpcodeSpecFile struct {
+	Game    string                       `json:"game,omitempty" toml:"game,omitempty"`     // Id of the game the opcodes belong to, empty for every game
+	Layout  *RDTLayout                   `json:"layout,omitempty" toml:"layout,omitempty"` // replaces the RDT layout of the game, e.g. for a beta build
+	Opcodes []OpcodeSpec                 `json:"opcodes" toml:"opcodes"`
+	Enums   map[string]map[string]string `json:"enums,omitempty" toml:"enums,omitempty"` // value names, keys are decimal numbers
+}
+
+// OpcodeSpec defines the layout of one opcode
+type OpcodeSpec struct {
+	Opcode int         `json:"opcode" toml:"opcode"`
+	Name   string      `json:"name" toml:"name"`
+	Size   int         `json:"size" toml:"size"`                         // size in bytes including the opcode
+	Fields []FieldSpec `json:"fields,omitempty" toml:"fields,omitempty"` // fields after the opcode; without fields the bytes are printed as a list
+
+	// Builtin is the name of an RE2 opcode with the same meaning and size, e.g. CheckBit.
+	// The opcode is then decoded, printed, decompiled and simulated like that opcode.
+	Builtin string `json:"builtin,omitempty" toml:"builtin,omitempty"`
+}
+
+// FieldSpec is a parameter of an opcode
+type FieldSpec struct {
+	Name  string `json:"name" toml:"name"`
+	Type  string `json:"type" toml:"type"`                      // u8, s8, u16, s16, u32 or s32
+	Count int    `json:"count,omitempty" toml:"count,omitzero"` // number of elements for arrays
+	Enum  string `json:"enum,omitempty" toml:"enum,omitempty"`  // name of an enum in the spec file or a table of the symbol table, e.g. items
+}
+
+// SpecInstruction is the decoded form of an opcode from a spec file whose size
+// differs from the built-in struct of the opcode, or which has no built-in struct
+type SpecInstruction struct {
+	Opcode byte
+	Fields []SpecFieldValue
+}
+
+// SpecFieldValue is the value of a field of a SpecInstruction. Fields that are not arrays have one value.
+type SpecFieldValue struct {
+	Name   string
+	Values []int64
+}
+
+// fieldTypeBits is the number of bits of each field type and whether it is signed
+var fieldTypeBits = map[string]struct {
+	bits   int
+	signed bool
+}{
+	"u8":  {8, false},
+	"s8":  {8, true},
+	"u16": {16, false},
+	"s16": {16, true},
+	"u32": {32, false},
+	"s32": {32, true},
+}
+
+// userOpcodeSpecs are the loaded spec files, which are applied to the opcodes of their game
+var userOpcodeSpecs = make([]*OpcodeSpecFile, 0)
+
+// OP_NO_MEANING is the canonical opcode of opcodes that have no RE2 counterpart
+const OP_NO_MEANING = 0xff
+
+// builtinFieldEnums are the symbol tables used by the built-in signatures, so that the
+// default spec shows names for the same fields
+var builtinFieldEnums = map[byte]map[string]string{
+	OP_CHECK:           {"BitArray": "bitArrays"},
+	OP_SET_BIT:         {"BitArray": "bitArrays"},
+	OP_DOOR_AOT_SET:    {"Stage": "stages", "KeyId": "items"},
+	OP_DOOR_AOT_SET_4P: {"Stage": "stages", "KeyId": "items"},
+	OP_SCE_EM_SET:      {"Type": "enemies"},
+	OP_ITEM_AOT_SET:    {"ItemId": "items"},
+	OP_ITEM_AOT_SET_4P: {"ItemId": "items"},
+	OP_KEEP_ITEM_CK:    {"ItemId": "items"},
+	OP_SCE_ITEM_LOST:   {"ItemId": "items"},
+	OP_XA_ON:           {"Id": "xa"},
+	OP_SCE_BGMTBL_SET:  {"Stage": "stages", "Data0": "bgm", "Data1": "bgm"},
+}
+
+// symbolEnumNames returns the names of a table of the symbol table that fields can use as enum
+func symbolEnumNames(enum string) (map[string]string, bool) {
+	switch enum {
+	case "items":
+		return Symbols.Items, true
+	case "enemies":
+		return Symbols.Enemies, true
+	case "bitArrays":
+		return Symbols.BitArrays, true
+	case "bgm":
+		return Symbols.Bgm, true
+	case "xa":
+		return Symbols.Xa, true
+	case "stages":
+		return Symbols.Stages, true
+	}
+	return nil, false
+}
+
+// builtinOpcodeSpec describes the ScriptInstr struct of an opcode
+func builtinOpcodeSpec(opcode byte) OpcodeSpec {
+	opcodeSpec := OpcodeSpec{Opcode: int(opcode), Name: FunctionName[opcode], Size: InstructionSize[opcode]}
+
+	// Decoding an empty instruction returns the zero value of the struct of the opcode
+	emptyLine := make([]byte, InstructionSize[opcode])
+	emptyLine[0] = opcode
+	zero, err := instructionDecoders[opcode](emptyLine)
+	if err != nil {
+		return opcodeSpec
+	}
+	structType := reflect.TypeOf(zero)
+	for i := 1; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldSpec := FieldSpec{Name: field.Name, Enum: builtinFieldEnums[opcode][field.Name]}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Array {
+			fieldSpec.Count = fieldType.Len()
+			fieldType = fieldType.Elem()
+		}
+		prefix := "u"
+		if fieldType.Kind() >= reflect.Int && fieldType.Kind() <= reflect.Int64 {
+			prefix = "s"
+		}
+		fieldSpec.Type = prefix + strconv.Itoa(fieldType.Bits())
+		opcodeSpec.Fields = append(opcodeSpec.Fields, fieldSpec)
+	}
+	return opcodeSpec
+}
+
+// ReadOpcodeSpec reads an opcode spec in the JSON or TOML format
+func ReadOpcodeSpec(r io.Reader, format string) (*OpcodeSpecFile, error) {
+	spec := &OpcodeSpecFile{}
+	switch format {
+	case "json":
+		if err := json.NewDecoder(r).Decode(spec); err != nil {
+			return nil, err
+		}
+	case "toml":
+		if _, err := toml.NewDecoder(r).Decode(spec); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown opcode spec format %q", format)
+	}
+	return spec, nil
+}
+
+// WriteTOML writes the spec in the TOML format that LoadOpcodeSpecFile reads
+func (spec *OpcodeSpecFile) WriteTOML(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(spec)
+}
+
+// WriteJSON writes the spec in the JSON format that LoadOpcodeSpecFile reads
+func (spec *OpcodeSpecFile) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(spec)
+}
+
+// LoadOpcodeSpecFile reads a .json or .toml spec file and applies it
+func LoadOpcodeSpecFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	spec, err := ReadOpcodeSpec(file, strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), "."))
+	if err == nil {
+		err = spec.Apply()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	return nil
+}
+
+// Validate checks that every opcode has a name and that its fields add up to its size
+func (spec *OpcodeSpecFile) Validate() error {
+	if spec.Game != "" {
+		if _, err := FindGame(spec.Game); err != nil {
+			return err
+		}
+	}
+	if spec.Layout != nil {
+		if spec.Game == "" {
+			return fmt.Errorf("a layout needs the game whose layout it replaces")
+		}
+		if err := spec.Layout.validate(); err != nil {
+			return fmt.Errorf("layout: %w", err)
+		}
+	}
+
+	names := make(map[string]int)
+	for _, opcodeSpec := range spec.Opcodes {
+		if opcodeSpec.Opcode < 0 || opcodeSpec.Opcode > 0xff {
+			return fmt.Errorf("opcode %d is not a byte", opcodeSpec.Opcode)
+		}
+		if opcodeSpec.Name == "" {
+			return fmt.Errorf("opcode 0x%02x has no name", opcodeSpec.Opcode)
+		}
+		if other, exists := names[opcodeSpec.Name]; exists && other != opcodeSpec.Opcode {
+			return fmt.Errorf("opcodes 0x%02x and 0x%02x are both named %s", other, opcodeSpec.Opcode, opcodeSpec.Name)
+		}
+		names[opcodeSpec.Name] = opcodeSpec.Opcode
+		if opcodeSpec.Size < 1 {
+			return fmt.Errorf("%s: size must include the opcode byte", opcodeSpec.Name)
+		}
+		if opcodeSpec.Builtin != "" {
+			builtin, exists := opcodeByName[opcodeSpec.Builtin]
+			if !exists {
+				return fmt.Errorf("%s: unknown builtin opcode %s", opcodeSpec.Name, opcodeSpec.Builtin)
+			}
+			if InstructionSize[builtin] != opcodeSpec.Size {
+				return fmt.Errorf("%s: size %d differs from the size %d of %s", opcodeSpec.Name, opcodeSpec.Size, InstructionSize[builtin], opcodeSpec.Builtin)
+			}
+		}
+
+		if len(opcodeSpec.Fields) == 0 {
+			continue
+		}
+		size := 1
+		fieldNames := make(map[string]bool)
+		for _, field := range opcodeSpec.Fields {
+			fieldType, exists := fieldTypeBits[field.Type]
+			if !exists {
+				return fmt.Errorf("%s: field %s has unknown type %q", opcodeSpec.Name, field.Name, field.Type)
+			}
+			if field.Name == "" || field.Name == "Opcode" || fieldNames[field.Name] {
+				return fmt.Errorf("%s: invalid or duplicate field name %q", opcodeSpec.Name, field.Name)
+			}
+			fieldNames[field.Name] = true
+			size += fieldType.bits / 8 * max(field.Count, 1)
+		}
+		if size != opcodeSpec.Size {
+			return fmt.Errorf("%s: fields have %d bytes, but the size is %d", opcodeSpec.Name, size, opcodeSpec.Size)
+		}
+	}
+
+	// A spec for every game has the numbers of the RE2 opcodes, e.g. the output of the opcodes command,
+	// so it is checked against the RE2 opcodes
+	game := GameRE2
+	if spec.Game != "" {
+		game, _ = FindGame(spec.Game)
+	}
+	opcodes, err := game.Opcodes()
+	if err != nil {
+		return err
+	}
+	return opcodes.checkSpec(spec, names)
+}
+
+// checkSpec checks that the fields of a spec use known enums, and that the opcodes of the spec
+// keep unique names. names maps the names in the spec to their opcodes.
+func (opcodes *OpcodeSet) checkSpec(spec *OpcodeSpecFile, names map[string]int) error {
+	for _, opcodeSpec := range spec.Opcodes {
+		for _, field := range opcodeSpec.Fields {
+			if field.Enum == "" {
+				continue
+			}
+			if _, exists := spec.Enums[field.Enum]; !exists {
+				if _, exists := opcodes.enumNames(field.Enum); !exists {
+					return fmt.Errorf("%s: field %s uses unknown enum %q", opcodeSpec.Name, field.Name, field.Enum)
+				}
+			}
+		}
+	}
+
+	// Names of other opcodes cannot be reused, because the assembler looks up opcodes by name,
+	// unless that opcode is renamed by the spec as well
+	newNames := make(map[int]string, len(spec.Opcodes))
+	for _, opcodeSpec := range spec.Opcodes {
+		newNames[opcodeSpec.Opcode] = opcodeSpec.Name
+	}
+	for name, opcode := range names {
+		other, exists := opcodes.byName[name]
+		if !exists || int(other) == opcode {
+			continue
+		}
+		if newName, renamed := newNames[int(other)]; !renamed || newName == name {
+			return fmt.Errorf("opcode 0x%02x cannot be named %s, which is the name of opcode 0x%02x", opcode, name, other)
+		}
+	}
+	return nil
+}
+
+// Apply validates the spec and adds it to the opcodes of its game, or of every game.
+// Rooms that are loaded afterwards are read with the new definitions.
+func (spec *OpcodeSpecFile) Apply() error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	userOpcodeSpecs = append(userOpcodeSpecs, spec)
+	resetOpcodeSets()
+	return nil
+}
+
+// ResetOpcodeSpecs removes the definitions of all loaded spec files and goes back to the
+// built-in opcodes of every game
+func ResetOpcodeSpecs() {
+	userOpcodeSpecs = userOpcodeSpecs[:0]
+	resetOpcodeSets()
+}
+
+// decode reads the fields of the spec into a SpecInstruction
+func (opcodeSpec OpcodeSpec) decode(lineBytes []byte) (any, error) {
+	if len(lineBytes) != opcodeSpec.Size {
+		return nil, fmt.Errorf("instruction has %d bytes, but %s has %d bytes", len(lineBytes), opcodeSpec.Name, opcodeSpec.Size)
+	}
+
+	instruction := SpecInstruction{Opcode: lineBytes[0], Fields: make([]SpecFieldValue, 0, len(opcodeSpec.Fields))}
+	offset := 1
+	for _, field := range opcodeSpec.Fields {
+		fieldType := fieldTypeBits[field.Type]
+		size := fieldType.bits / 8
+		value := SpecFieldValue{Name: field.Name, Values: make([]int64, max(field.Count, 1))}
+		for i := range value.Values {
+			var number uint64
+			for b := size - 1; b >= 0; b-- {
+				number = number<<8 | uint64(lineBytes[offset+b])
+			}
+			if fieldType.signed {
+				value.Values[i] = int64(number<<(64-fieldType.bits)) >> (64 - fieldType.bits)
+			} else {
+				value.Values[i] = int64(number)
+			}
+			offset += size
+		}
+		instruction.Fields = append(instruction.Fields, value)
+	}
+	return instruction, nil
+}
+
+// formatParams prints the fields of the spec in the same format as the built-in signatures,
+// with the names of the enums of the fields. An opcode with the size of its RE2 struct is decoded
+// into that struct, so its fields are read from the bytes of the instruction.
+func (opcodeSpec OpcodeSpec) formatParams(instruction Instruction, enumNames func(string) (map[string]string, bool)) string {
+	specInstruction, ok := instruction.Decoded().(SpecInstruction)
+	if !ok {
+		decoded, err := opcodeSpec.decode(instruction.Bytes())
+		if err != nil {
+			return formatDefaultParams(instruction.Bytes())
+		}
+		specInstruction = decoded.(SpecInstruction)
+	}
+
+	params := make([]string, 0, len(opcodeSpec.Fields))
+	for i, value := range specInstruction.Fields {
+		field := opcodeSpec.Fields[i]
+		if field.Count > 0 {
+			elements := make([]string, len(value.Values))
+			for j, element := range value.Values {
+				elements[j] = strconv.FormatInt(element, 10)
+			}
+			params = append(params, fmt.Sprintf("%s=[%s]", field.Name, strings.Join(elements, ",")))
+			continue
+		}
+
+		comment := ""
+		if names, exists := enumNames(field.Enum); exists {
+			comment = formatSymbolComment(names[strconv.FormatInt(value.Values[0], 10)])
+		}
+		params = append(params, fmt.Sprintf("%s=%d%s", field.Name, value.Values[0], comment))
+	}
+	return strings.Join(params, ", ")
+}
+
+// assemble writes the Field=value parameters as the bytecode of the spec.
+// Fields that are not given are 0.
+func (opcodeSpec OpcodeSpec) assemble(params []string) ([]byte, error) {
+	values := make(map[string]string, len(params))
+	for _, param := range params {
+		fieldName, value, found := strings.Cut(param, "=")
+		if !found {
+			return nil, fmt.Errorf("%s: expected Field=value but got %q", opcodeSpec.Name, param)
+		}
+		values[strings.TrimSpace(fieldName)] = strings.TrimSpace(value)
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteByte(byte(opcodeSpec.Opcode))
+	for _, field := range opcodeSpec.Fields {
+		value, exists := values[field.Name]
+		delete(values, field.Name)
+		elements := []string{value}
+		if !exists {
+			elements = make([]string, max(field.Count, 1))
+			for i := range elements {
+				elements[i] = "0"
+			}
+		} else if field.Count > 0 {
+			if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+				return nil, fmt.Errorf("%s: field %s: expected an array but got %q", opcodeSpec.Name, field.Name, value)
+			}
+			elements = splitParams(value[1 : len(value)-1])
+			if len(elements) != field.Count {
+				return nil, fmt.Errorf("%s: field %s: expected %d values but got %d", opcodeSpec.Name, field.Name, field.Count, len(elements))
+			}
+		}
+
+		bits := fieldTypeBits[field.Type].bits
+		for _, element := range elements {
+			number, err := parseFieldNumber(element, bits)
+			if err != nil {
+				return nil, fmt.Errorf("%s: field %s: %w", opcodeSpec.Name, field.Name, err)
+			}
+			var fieldBytes [8]byte
+			binary.LittleEndian.PutUint64(fieldBytes[:], uint64(number))
+			buffer.Write(fieldBytes[:bits/8])
+		}
+	}
+	for fieldName := range values {
+		return nil, fmt.Errorf("%s has no field %s", opcodeSpec.Name, fieldName)
+	}
+	return buffer.Bytes(), nil
+}
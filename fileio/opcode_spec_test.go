@@ -0,0 +1,38 @@
+package fileio
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSpecResizesBuiltinOpcode(t *testing.T) {
+	spec, err := ReadOpcodeSpec(strings.NewReader("[[opcodes]]\nopcode = 0x22\nname = \"SetBit\"\nsize = 2\n"), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spec.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	defer ResetOpcodeSpecs()
+
+	opcodes, err := GameRE2.Opcodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opcode := opcodes.CanonicalOpcode(OP_SET_BIT); opcode != OP_NO_MEANING {
+		t.Errorf("resized SetBit has the meaning of opcode 0x%02x, want none", opcode)
+	}
+
+	script := BuildSCD([][]byte{{OP_SET_BIT, 1, OP_EVT_END}})
+	output, err := LoadRDT_SCDStream(bytes.NewReader(script), int64(len(script)), opcodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, isSetBit := output.ScriptData.Program[0].Decoded().(ScriptInstrSetBit); isSetBit {
+		t.Error("resized SetBit is decoded into the struct of SetBit")
+	}
+	if len(output.Diagnostics) != 1 || output.Diagnostics[0].Offset != 2 {
+		t.Errorf("got diagnostics %v, want a warning at offset 2", output.Diagnostics)
+	}
+}
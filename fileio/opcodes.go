@@ -150,7 +150,7 @@ const (
 	OP_SCE_PARTS_DOWN = 123
 )
 
-// InstructionSize maps opcodes to their byte sizes
+// InstructionSize maps the RE2 opcodes to their byte sizes. The opcodes of a room are in its OpcodeSet.
 var InstructionSize = map[byte]int{
 	OP_NO_OP:            1,
 	OP_EVT_END:          1,
@@ -257,7 +257,7 @@ var InstructionSize = map[byte]int{
 	OP_SCE_PARTS_DOWN:   16,
 }
 
-// FunctionName maps opcodes to their human-readable names
+// FunctionName maps the RE2 opcodes to their human-readable names
 var FunctionName = map[byte]string{
 	OP_NO_OP:            "NoOp",
 	OP_EVT_END:          "EvtEnd",
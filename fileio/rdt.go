@@ -4,9 +4,13 @@ package fileio
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 type RDTHeader struct {
@@ -47,11 +51,62 @@ type RDTOffsets struct {
 }
 
 type RDTOutput struct {
-	InitScriptData *SCDOutput
-	RoomScriptData *SCDOutput
+	InitScriptData     *SCDOutput
+	RoomScriptData     *SCDOutput
+	Lang1MessageData   *MSGOutput
+	Lang2MessageData   *MSGOutput
+	CameraPositionData *RIDOutput
+	CameraSwitchData   *RVDOutput
+	CollisionData      *SCAOutput
+	Diagnostics        []*Diagnostic // problems skipped while parsing, empty for an intact room
+	Game               *Game         // game whose layout and opcodes were used to parse the room
+	Opcodes            *OpcodeSet    // opcodes of the game, used to print and assemble the scripts of the room
 }
 
+// RoomId returns the number of a room as used in the file names, e.g. 100 for the first room of the first stage.
+// The stage number in the scripts starts at 0, but the file names start at 1.
+func RoomId(stage uint8, room uint8) string {
+	return fmt.Sprintf("%d%02X", int(stage)+1, room)
+}
+
+// RoomFileName returns the name of the RDT file of a room, e.g. ROOM1000 for the first room of the first stage
+// played by Leon. The last digit is the player, 0 for Leon and 1 for Claire.
+func RoomFileName(stage uint8, room uint8, player uint8) string {
+	return fmt.Sprintf("ROOM%s%d", RoomId(stage, room), player)
+}
+
+// ParseRoomFileName returns the stage, room and player of an RDT file name such as ROOM1000.RDT
+func ParseRoomFileName(filename string) (uint8, uint8, uint8, bool) {
+	name := strings.ToUpper(strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)))
+	if len(name) != 8 || !strings.HasPrefix(name, "ROOM") {
+		return 0, 0, 0, false
+	}
+	stage, err := strconv.ParseUint(name[4:5], 10, 8)
+	if err != nil || stage == 0 {
+		return 0, 0, 0, false
+	}
+	room, err := strconv.ParseUint(name[5:7], 16, 8)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	player, err := strconv.ParseUint(name[7:8], 10, 8)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return uint8(stage - 1), uint8(room), uint8(player), true
+}
+
+// LoadRDTFile loads an RDT file, or a room on a disc image if the name has the form of DiscRoomPath
 func LoadRDTFile(filename string) (*RDTOutput, error) {
+	if image, path, ok := SplitDiscRoomPath(filename); ok {
+		disc, err := OpenDiscImage(image)
+		if err != nil {
+			return nil, err
+		}
+		defer disc.Close()
+		return disc.LoadRDT(path)
+	}
+
 	rdtFile, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open RDT file %s: %w", filename, err)
@@ -64,7 +119,23 @@ func LoadRDTFile(filename string) (*RDTOutput, error) {
 	}
 
 	fileLength := fi.Size()
-	return LoadRDT(rdtFile, fileLength)
+	output, err := LoadRDT(rdtFile, fileLength)
+	return output, setDiagnosticsFile(output, err, filename)
+}
+
+// setDiagnosticsFile sets the file of the problems of a loaded room, or of the error that stopped loading it
+func setDiagnosticsFile(output *RDTOutput, err error, filename string) error {
+	if err != nil {
+		var diagnostic *Diagnostic
+		if errors.As(err, &diagnostic) {
+			diagnostic.File = filename
+		}
+		return err
+	}
+	for _, diagnostic := range output.Diagnostics {
+		diagnostic.File = filename
+	}
+	return nil
 }
 
 func LoadRDT(r io.ReaderAt, fileLength int64) (*RDTOutput, error) {
@@ -75,31 +146,178 @@ func LoadRDT(r io.ReaderAt, fileLength int64) (*RDTOutput, error) {
 		return nil, err
 	}
 
-	offsets := RDTOffsets{}
-	if err := binary.Read(reader, binary.LittleEndian, &offsets); err != nil {
+	// Opcodes and section layout of the game
+	game := SelectedGame
+	if game == nil {
+		game = DetectGame(r, fileLength)
+	}
+	opcodes, err := game.Opcodes()
+	if err != nil {
+		return nil, err
+	}
+	layout := game.roomLayout()
+	offsets, err := readRDTOffsets(r, fileLength, layout)
+	if err != nil {
 		return nil, err
 	}
+	initScriptOffset := layout.sectionOffset(offsets, SectionInitScript)
+	roomScriptOffset := layout.sectionOffset(offsets, SectionRoomScript)
+	lang1Offset := layout.sectionOffset(offsets, SectionLang1)
+	lang2Offset := layout.sectionOffset(offsets, SectionLang2)
+	cameraPositionOffset := layout.sectionOffset(offsets, SectionCameraPositions)
+	cameraSwitchesOffset := layout.sectionOffset(offsets, SectionCameraSwitches)
+	collisionOffset := layout.sectionOffset(offsets, SectionCollision)
+
+	diagnostics := make([]*Diagnostic, 0)
 
 	// Script data
 	// Run once when the level loads
-	offset := int64(offsets.OffsetInitScript)
-	initSCDReader := io.NewSectionReader(r, offset, fileLength-offset)
-	initSCDOutput, err := LoadRDT_SCDStream(initSCDReader, fileLength)
+	initSCDOutput, err := loadScriptSection(r, layout, initScriptOffset, fileLength, SectionInitScript, opcodes, &diagnostics)
 	if err != nil {
 		return nil, err
 	}
 
 	// Run during the game
-	offset = int64(offsets.OffsetExecuteScript)
-	roomSCDReader := io.NewSectionReader(r, offset, fileLength-offset)
-	roomSCDOutput, err := LoadRDT_SCDStream(roomSCDReader, fileLength)
+	roomSCDOutput, err := loadScriptSection(r, layout, roomScriptOffset, fileLength, SectionRoomScript, opcodes, &diagnostics)
 	if err != nil {
 		return nil, err
 	}
 
+	// Message text
+	// The font of the Japanese text in Lang1 is not known
+	lang1MSGOutput, err := loadMessageSection(r, lang1Offset, fileLength, nil)
+	if err != nil {
+		if err := reportSectionProblem(&diagnostics, SectionLang1, lang1Offset, err); err != nil {
+			return nil, err
+		}
+		lang1MSGOutput = &MSGOutput{Messages: make([]string, 0)}
+	}
+	lang2MSGOutput, err := loadMessageSection(r, lang2Offset, fileLength, messageCharacters)
+	if err != nil {
+		if err := reportSectionProblem(&diagnostics, SectionLang2, lang2Offset, err); err != nil {
+			return nil, err
+		}
+		lang2MSGOutput = &MSGOutput{Messages: make([]string, 0)}
+	}
+
+	// Camera positions
+	offset := int64(cameraPositionOffset)
+	ridOutput, err := loadSection(r, layout, SectionCameraPositions, offset, fileLength, func(reader *io.SectionReader) (*RIDOutput, error) {
+		return LoadRDT_RIDStream(reader, fileLength-offset, int(rdtHeader.NumCameras))
+	})
+	if err != nil {
+		if err := reportSectionProblem(&diagnostics, SectionCameraPositions, cameraPositionOffset, err); err != nil {
+			return nil, err
+		}
+		ridOutput = &RIDOutput{Cameras: make([]RIDCamera, 0)}
+	}
+
+	// Camera switches
+	offset = int64(cameraSwitchesOffset)
+	rvdOutput, err := loadSection(r, layout, SectionCameraSwitches, offset, fileLength, func(reader *io.SectionReader) (*RVDOutput, error) {
+		return LoadRDT_RVDStream(reader, fileLength-offset)
+	})
+	if err != nil {
+		if err := reportSectionProblem(&diagnostics, SectionCameraSwitches, cameraSwitchesOffset, err); err != nil {
+			return nil, err
+		}
+		rvdOutput = &RVDOutput{CameraSwitches: make([]RVDCameraSwitch, 0)}
+	}
+
+	// Collision boundaries
+	offset = int64(collisionOffset)
+	scaOutput, err := loadSection(r, layout, SectionCollision, offset, fileLength, func(reader *io.SectionReader) (*SCAOutput, error) {
+		return LoadRDT_SCAStream(reader, fileLength-offset)
+	})
+	if err != nil {
+		if err := reportSectionProblem(&diagnostics, SectionCollision, collisionOffset, err); err != nil {
+			return nil, err
+		}
+		scaOutput = &SCAOutput{Elements: make([]SCAElement, 0)}
+	}
+
 	output := &RDTOutput{
-		InitScriptData: initSCDOutput,
-		RoomScriptData: roomSCDOutput,
+		InitScriptData:     initSCDOutput,
+		RoomScriptData:     roomSCDOutput,
+		Lang1MessageData:   lang1MSGOutput,
+		Lang2MessageData:   lang2MSGOutput,
+		CameraPositionData: ridOutput,
+		CameraSwitchData:   rvdOutput,
+		CollisionData:      scaOutput,
+		Diagnostics:        diagnostics,
+		Game:               game,
+		Opcodes:            opcodes,
 	}
 	return output, nil
 }
+
+// loadScriptSection parses the script at the offset and adds its problems to the diagnostics of the room
+func loadScriptSection(r io.ReaderAt, layout RDTLayout, offset uint32, fileLength int64, section string, opcodes *OpcodeSet, diagnostics *[]*Diagnostic) (*SCDOutput, error) {
+	if !layout.hasSection(section) {
+		return newSCDOutput(), nil
+	}
+	if int64(offset) >= fileLength {
+		err := reportSectionProblem(diagnostics, section, offset, fmt.Errorf("offset is after the end of the file"))
+		return newSCDOutput(), err
+	}
+
+	scdReader := io.NewSectionReader(r, int64(offset), fileLength-int64(offset))
+	loadScript := LoadRDT_SCDStream
+	if layout.ScriptBlocks {
+		loadScript = LoadRDT_SCDBlockStream
+	}
+	scdOutput, err := loadScript(scdReader, fileLength-int64(offset), opcodes)
+	if err != nil {
+		var diagnostic *Diagnostic
+		if errors.As(err, &diagnostic) {
+			relocateDiagnostics([]*Diagnostic{diagnostic}, section, int64(offset))
+		}
+		return nil, err
+	}
+	relocateDiagnostics(scdOutput.Diagnostics, section, int64(offset))
+	*diagnostics = append(*diagnostics, scdOutput.Diagnostics...)
+	return scdOutput, nil
+}
+
+// errNoSection is returned for sections that the rooms of the game do not have
+var errNoSection = errors.New("the game has no such section")
+
+// loadSection parses the section at the offset with the parser of the section
+func loadSection[T any](r io.ReaderAt, layout RDTLayout, section string, offset int64, fileLength int64, parse func(reader *io.SectionReader) (*T, error)) (*T, error) {
+	if !layout.hasSection(section) {
+		return nil, errNoSection
+	}
+	if offset >= fileLength {
+		return nil, fmt.Errorf("offset is after the end of the file")
+	}
+	return parse(io.NewSectionReader(r, offset, fileLength-offset))
+}
+
+// reportSectionProblem records a section that cannot be parsed. Outside of strict mode the room is loaded without it.
+// Sections that the game does not have are left out without a problem.
+func reportSectionProblem(diagnostics *[]*Diagnostic, section string, offset uint32, err error) error {
+	if errors.Is(err, errNoSection) {
+		return nil
+	}
+	return reportProblem(diagnostics, &Diagnostic{
+		Severity: SeverityError,
+		Section:  section,
+		Function: NoFunction,
+		Offset:   int64(offset),
+		Message:  err.Error(),
+	})
+}
+
+// loadMessageSection parses the messages at the offset, rooms without messages have an offset of 0
+func loadMessageSection(r io.ReaderAt, offset uint32, fileLength int64, characters []string) (*MSGOutput, error) {
+	if offset == 0 || int64(offset) >= fileLength {
+		return &MSGOutput{Messages: make([]string, 0)}, nil
+	}
+
+	msgReader := io.NewSectionReader(r, int64(offset), fileLength-int64(offset))
+	msgOutput, err := LoadRDT_MSGStream(msgReader, fileLength-int64(offset), characters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	return msgOutput, nil
+}
@@ -0,0 +1,154 @@
+package fileio
+
+// .msg - Message text parsing logic
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MSGOutput represents the parsed output from a message text file
+type MSGOutput struct {
+	Messages []string // decoded text of every message, indexed by message id
+	Decoded  bool     // false if the font of the language is not known and every character is shown as {xx}
+}
+
+// Control codes in the message text
+const (
+	MSG_EXTENDED_CHAR = 0xea // 2 byte character, the next byte selects the character
+	MSG_ITEM_NAME     = 0xf8 // insert the name of an item
+	MSG_COLOR         = 0xf9 // change the text color
+	MSG_TEXT_START    = 0xfa // start of the text, the parameter is the display speed
+	MSG_YES_NO        = 0xfb // show a yes/no prompt
+	MSG_LINE_BREAK    = 0xfc // start a new line
+	MSG_PAUSE         = 0xfd // wait for a button press before showing the next page
+	MSG_END           = 0xfe // end of the message
+)
+
+// messageControlCodes maps control codes to the tag shown in the decoded text and their parameter count
+var messageControlCodes = map[byte]struct {
+	tag        string
+	paramCount int
+}{
+	MSG_EXTENDED_CHAR: {"char", 1},
+	MSG_ITEM_NAME:     {"item", 1},
+	MSG_COLOR:         {"color", 1},
+	MSG_TEXT_START:    {"start", 1},
+	MSG_YES_NO:        {"yes/no", 1},
+	MSG_LINE_BREAK:    {"", 0},
+	MSG_PAUSE:         {"pause", 1},
+	MSG_END:           {"", 1},
+}
+
+// messageCharacters is the font table of the English text in the Lang2 block.
+// Characters missing from the table are shown as {xx} with the hex value. The font of
+// the Japanese text in the Lang1 block is not known, so all of its characters are shown that way.
+var messageCharacters = []string{
+	" ", ".", "…", "(", ")", "«", "»", "'", "\"", "_", "0", "1", "2", "3", "4", "5",
+	"6", "7", "8", "9", ":", "·", ",", "\"", "!", "?", "_", "A", "B", "C", "D", "E",
+	"F", "G", "H", "I", "J", "K", "L", "M", "N", "O", "P", "Q", "R", "S", "T", "U",
+	"V", "W", "X", "Y", "Z", "[", "/", "]", "'", "-", "_", "a", "b", "c", "d", "e",
+	"f", "g", "h", "i", "j", "k", "l", "m", "n", "o", "p", "q", "r", "s", "t", "u",
+	"v", "w", "x", "y", "z",
+}
+
+// LoadRDT_MSGStream parses the messages of a language block with the font table of the language,
+// which is nil if the font is not known
+func LoadRDT_MSGStream(fileReader io.ReaderAt, fileLength int64, characters []string) (*MSGOutput, error) {
+	streamReader := io.NewSectionReader(fileReader, int64(0), fileLength)
+	firstOffset := uint16(0)
+	if err := binary.Read(streamReader, binary.LittleEndian, &firstOffset); err != nil {
+		return nil, err
+	}
+
+	messageOffsets := make([]uint16, 0)
+	messageOffsets = append(messageOffsets, firstOffset)
+	for i := 2; i < int(firstOffset); i += 2 {
+		nextOffset := uint16(0)
+		if err := binary.Read(streamReader, binary.LittleEndian, &nextOffset); err != nil {
+			return nil, err
+		}
+		messageOffsets = append(messageOffsets, nextOffset)
+	}
+
+	messages := make([]string, 0, len(messageOffsets))
+	for _, messageOffset := range messageOffsets {
+		if int64(messageOffset) >= fileLength {
+			messages = append(messages, "")
+			continue
+		}
+
+		messageReader := bufio.NewReader(io.NewSectionReader(fileReader, int64(messageOffset), fileLength-int64(messageOffset)))
+		text, err := decodeMessage(messageReader, characters)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("error reading message at offset %d: %w", messageOffset, err)
+		}
+		messages = append(messages, text)
+	}
+
+	output := &MSGOutput{
+		Messages: messages,
+		Decoded:  characters != nil,
+	}
+	return output, nil
+}
+
+// MessageLanguageNote returns a note for the heading of a language block whose characters are not decoded
+func MessageLanguageNote(msgOutput *MSGOutput) string {
+	if msgOutput.Decoded || len(msgOutput.Messages) == 0 {
+		return ""
+	}
+	return " (font not known, characters are shown as {xx})"
+}
+
+// DecodeMessage converts the bytes of a single English message to text
+func DecodeMessage(data []byte) string {
+	text, _ := decodeMessage(bufio.NewReader(strings.NewReader(string(data))), messageCharacters)
+	return text
+}
+
+// decodeMessage reads characters with the font table until the end of the message
+func decodeMessage(reader io.ByteReader, characters []string) (string, error) {
+	var builder strings.Builder
+	for {
+		char, err := reader.ReadByte()
+		if err != nil {
+			return builder.String(), err
+		}
+
+		control, isControl := messageControlCodes[char]
+		if !isControl {
+			if int(char) < len(characters) {
+				builder.WriteString(characters[char])
+			} else {
+				builder.WriteString(fmt.Sprintf("{%02x}", char))
+			}
+			continue
+		}
+
+		params := make([]byte, control.paramCount)
+		for i := range params {
+			if params[i], err = reader.ReadByte(); err != nil {
+				return builder.String(), err
+			}
+		}
+
+		switch char {
+		case MSG_END:
+			return builder.String(), nil
+		case MSG_LINE_BREAK:
+			builder.WriteString("\n")
+		case MSG_TEXT_START:
+			// Only affects how fast the text is shown
+		default:
+			builder.WriteString("{" + control.tag)
+			for _, param := range params {
+				builder.WriteString(fmt.Sprintf(" %d", param))
+			}
+			builder.WriteString("}")
+		}
+	}
+}
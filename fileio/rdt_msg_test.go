@@ -0,0 +1,66 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodeMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"characters", []byte{0x22, 0x3f, 0x46, 0x46, 0x49, 0x00, 0x19, MSG_END, 0}, "Hello ?"},
+		{"text start is hidden", []byte{MSG_TEXT_START, 2, 0x1b, MSG_END, 0}, "A"},
+		{"line break", []byte{0x1b, MSG_LINE_BREAK, 0x1c, MSG_END, 0}, "A\nB"},
+		{"color", []byte{MSG_COLOR, 1, 0x1b, MSG_COLOR, 0, MSG_END, 0}, "{color 1}A{color 0}"},
+		{"item name", []byte{MSG_ITEM_NAME, 38, MSG_END, 0}, "{item 38}"},
+		{"yes/no and pause", []byte{MSG_YES_NO, 0, MSG_PAUSE, 3, MSG_END, 0}, "{yes/no 0}{pause 3}"},
+		{"extended character", []byte{MSG_EXTENDED_CHAR, 5, MSG_END, 0}, "{char 5}"},
+		{"character outside the font", []byte{0x1b, 0x80, MSG_END, 0}, "A{80}"},
+		{"text after the end", []byte{0x1b, MSG_END, 0, 0x1c}, "A"},
+		{"missing end", []byte{0x1b, 0x1c}, "AB"},
+		{"missing parameter", []byte{0x1b, MSG_COLOR}, "A"},
+	}
+	for _, test := range tests {
+		if got := DecodeMessage(test.data); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadRDT_MSGStreamWithoutFont(t *testing.T) {
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, []uint16{4, 7})
+	buffer.Write([]byte{0x1b, MSG_END, 0, MSG_COLOR, 1, 0x1c, MSG_END, 0})
+	data := buffer.Bytes()
+
+	tests := []struct {
+		name       string
+		characters []string
+		want       []string
+		wantNote   bool
+	}{
+		{"Lang2 font", messageCharacters, []string{"A", "{color 1}B"}, false},
+		{"Lang1 without font", nil, []string{"{1b}", "{color 1}{1c}"}, true},
+	}
+	for _, test := range tests {
+		output, err := LoadRDT_MSGStream(bytes.NewReader(data), int64(len(data)), test.characters)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(output.Messages) != len(test.want) {
+			t.Fatalf("%s: got %d messages, want %d", test.name, len(output.Messages), len(test.want))
+		}
+		for i, message := range output.Messages {
+			if message != test.want[i] {
+				t.Errorf("%s: message %d is %q, want %q", test.name, i, message, test.want[i])
+			}
+		}
+		if hasNote := MessageLanguageNote(output) != ""; hasNote != test.wantNote {
+			t.Errorf("%s: got note %q", test.name, MessageLanguageNote(output))
+		}
+	}
+}
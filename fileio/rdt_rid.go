@@ -0,0 +1,70 @@
+package fileio
+
+// .rid - Camera position parsing logic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// RIDCamera is the position and projection of one of the room's cameras.
+// The camera index is also the index of the background image of the room.
+type RIDCamera struct {
+	Flag               uint16
+	ProjectionConstant uint16 // distance from the eye to the screen, larger values zoom in
+	CameraFromX        int32  // eye position
+	CameraFromY        int32
+	CameraFromZ        int32
+	CameraToX          int32 // look-at target
+	CameraToY          int32
+	CameraToZ          int32
+	MaskOffset         uint32 // offset to the .pri sprites drawn over the background image
+}
+
+// RIDOutput represents the parsed output from a camera position file
+type RIDOutput struct {
+	Cameras []RIDCamera
+}
+
+func LoadRDT_RIDStream(fileReader io.ReaderAt, fileLength int64, numCameras int) (*RIDOutput, error) {
+	streamReader := io.NewSectionReader(fileReader, int64(0), fileLength)
+
+	maxCameras := fileLength / int64(binary.Size(RIDCamera{}))
+	if int64(numCameras) > maxCameras {
+		return nil, fmt.Errorf("camera positions have %d cameras, but only %d fit into the file", numCameras, maxCameras)
+	}
+	cameras := make([]RIDCamera, numCameras)
+	if err := binary.Read(streamReader, binary.LittleEndian, &cameras); err != nil {
+		return nil, err
+	}
+
+	output := &RIDOutput{
+		Cameras: cameras,
+	}
+	return output, nil
+}
+
+// Describe returns a short description of the camera position
+func (camera RIDCamera) Describe() string {
+	return fmt.Sprintf("eye [%d, %d, %d] -> target [%d, %d, %d]",
+		camera.CameraFromX, camera.CameraFromY, camera.CameraFromZ,
+		camera.CameraToX, camera.CameraToY, camera.CameraToZ)
+}
+
+// ConvertCamerasToString prints a table of every camera in the room
+func ConvertCamerasToString(ridOutput *RIDOutput) string {
+	var builder strings.Builder
+	writer := tabwriter.NewWriter(&builder, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "Camera\tFlag\tProjection\tEye\tTarget\tMask offset")
+	for i, camera := range ridOutput.Cameras {
+		fmt.Fprintf(writer, "%d\t%d\t%d\t[%d, %d, %d]\t[%d, %d, %d]\t0x%x\n",
+			i, camera.Flag, camera.ProjectionConstant,
+			camera.CameraFromX, camera.CameraFromY, camera.CameraFromZ,
+			camera.CameraToX, camera.CameraToY, camera.CameraToZ, camera.MaskOffset)
+	}
+	writer.Flush()
+	return builder.String()
+}
@@ -0,0 +1,53 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestLoadRDT_RIDStream(t *testing.T) {
+	cameras := []RIDCamera{
+		{Flag: 0, ProjectionConstant: 600, CameraFromX: -1000, CameraFromY: -2000, CameraFromZ: 3000, MaskOffset: 0x100},
+		{Flag: 1, ProjectionConstant: 700, CameraToX: 10, CameraToY: 20, CameraToZ: 30},
+	}
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, cameras)
+	data := buffer.Bytes()
+
+	tests := []struct {
+		name       string
+		data       []byte
+		numCameras int
+		wantErr    string
+	}{
+		{"all cameras", data, 2, ""},
+		{"fewer cameras than in the file", data, 1, ""},
+		{"no cameras", data, 0, ""},
+		{"more cameras than fit", data, 3, "camera positions have 3 cameras, but only 2 fit into the file"},
+		{"truncated camera", data[:len(data)-1], 2, "but only 1 fit into the file"},
+		{"empty section", nil, 1, "but only 0 fit into the file"},
+	}
+	for _, test := range tests {
+		output, err := LoadRDT_RIDStream(bytes.NewReader(test.data), int64(len(test.data)), test.numCameras)
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(output.Cameras) != test.numCameras {
+			t.Fatalf("%s: got %d cameras, want %d", test.name, len(output.Cameras), test.numCameras)
+		}
+		for i, camera := range output.Cameras {
+			if camera != cameras[i] {
+				t.Errorf("%s: camera %d is %+v, want %+v", test.name, i, camera, cameras[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package fileio
+
+// .rvd - Camera switch parsing logic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// rvdEndMarker is the flag value of the entry after the last camera switch
+const rvdEndMarker = 0xffff
+
+// RVDCameraSwitch is a quad on the floor that changes the camera when the player walks into it
+type RVDCameraSwitch struct {
+	Flag       uint16
+	FromCamera uint8 // camera that is active while the switch is checked
+	ToCamera   uint8 // camera that is shown after the player enters the quad
+	X1, Z1     int16 // corners of the quad
+	X2, Z2     int16
+	X3, Z3     int16
+	X4, Z4     int16
+}
+
+// RVDOutput represents the parsed output from a camera switch file
+type RVDOutput struct {
+	CameraSwitches []RVDCameraSwitch
+}
+
+func LoadRDT_RVDStream(fileReader io.ReaderAt, fileLength int64) (*RVDOutput, error) {
+	streamReader := io.NewSectionReader(fileReader, int64(0), fileLength)
+
+	cameraSwitches := make([]RVDCameraSwitch, 0)
+	for {
+		cameraSwitch := RVDCameraSwitch{}
+		if err := binary.Read(streamReader, binary.LittleEndian, &cameraSwitch); err != nil {
+			return nil, fmt.Errorf("camera switches end without the end marker after %d switches: %w", len(cameraSwitches), err)
+		}
+		if cameraSwitch.Flag == rvdEndMarker {
+			break
+		}
+		cameraSwitches = append(cameraSwitches, cameraSwitch)
+	}
+
+	output := &RVDOutput{
+		CameraSwitches: cameraSwitches,
+	}
+	return output, nil
+}
+
+// SwitchesFromCamera returns the camera switches that are checked while the camera is active
+func (rvdOutput *RVDOutput) SwitchesFromCamera(cameraId int) []RVDCameraSwitch {
+	cameraSwitches := make([]RVDCameraSwitch, 0)
+	for _, cameraSwitch := range rvdOutput.CameraSwitches {
+		if int(cameraSwitch.FromCamera) == cameraId {
+			cameraSwitches = append(cameraSwitches, cameraSwitch)
+		}
+	}
+	return cameraSwitches
+}
+
+// ConvertCameraSwitchesToString prints the camera switches of every camera in the room
+func ConvertCameraSwitchesToString(ridOutput *RIDOutput, rvdOutput *RVDOutput) string {
+	var builder strings.Builder
+	for cameraId := range ridOutput.Cameras {
+		cameraSwitches := rvdOutput.SwitchesFromCamera(cameraId)
+		builder.WriteString(fmt.Sprintf("Camera %d: %d switches\n", cameraId, len(cameraSwitches)))
+
+		writer := tabwriter.NewWriter(&builder, 0, 4, 2, ' ', 0)
+		for _, cameraSwitch := range cameraSwitches {
+			fmt.Fprintf(writer, "  -> camera %d\tflag %d\t[%d, %d] [%d, %d] [%d, %d] [%d, %d]\n",
+				cameraSwitch.ToCamera, cameraSwitch.Flag,
+				cameraSwitch.X1, cameraSwitch.Z1, cameraSwitch.X2, cameraSwitch.Z2,
+				cameraSwitch.X3, cameraSwitch.Z3, cameraSwitch.X4, cameraSwitch.Z4)
+		}
+		writer.Flush()
+	}
+	return builder.String()
+}
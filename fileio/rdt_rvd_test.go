@@ -0,0 +1,55 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// rvdData builds a list of camera switches followed by the end marker
+func rvdData(cameraSwitches ...RVDCameraSwitch) []byte {
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, cameraSwitches)
+	binary.Write(&buffer, binary.LittleEndian, RVDCameraSwitch{Flag: rvdEndMarker})
+	return buffer.Bytes()
+}
+
+func TestLoadRDT_RVDStream(t *testing.T) {
+	cameraSwitch := RVDCameraSwitch{Flag: 1, FromCamera: 0, ToCamera: 2, X1: -100, Z1: -100, X2: 100, Z2: -100, X3: 100, Z3: 100, X4: -100, Z4: 100}
+	data := rvdData(cameraSwitch, cameraSwitch)
+	tests := []struct {
+		name         string
+		data         []byte
+		wantSwitches int
+		wantErr      string
+	}{
+		{"two switches", data, 2, ""},
+		{"only the end marker", rvdData(), 0, ""},
+		{"data after the end marker", append(rvdData(cameraSwitch), 1, 2, 3), 1, ""},
+		{"no end marker", data[:2*binary.Size(cameraSwitch)], 0, "end without the end marker after 2 switches"},
+		{"truncated switch", data[:binary.Size(cameraSwitch)+5], 0, "end without the end marker after 1 switches"},
+		{"empty section", nil, 0, "end without the end marker after 0 switches"},
+	}
+	for _, test := range tests {
+		output, err := LoadRDT_RVDStream(bytes.NewReader(test.data), int64(len(test.data)))
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(output.CameraSwitches) != test.wantSwitches {
+			t.Errorf("%s: got %d camera switches, want %d", test.name, len(output.CameraSwitches), test.wantSwitches)
+		}
+		for _, got := range output.CameraSwitches {
+			if got != cameraSwitch {
+				t.Errorf("%s: got camera switch %+v, want %+v", test.name, got, cameraSwitch)
+			}
+		}
+	}
+}
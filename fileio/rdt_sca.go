@@ -0,0 +1,151 @@
+package fileio
+
+// .sca - Collision boundary parsing logic
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Collision shapes stored in the lower 4 bits of the element id
+const (
+	SCA_SHAPE_RECTANGLE      = 0
+	SCA_SHAPE_TRIANGLE_1     = 1 // right angle at the top left corner
+	SCA_SHAPE_TRIANGLE_2     = 2 // right angle at the top right corner
+	SCA_SHAPE_TRIANGLE_3     = 3 // right angle at the bottom right corner
+	SCA_SHAPE_TRIANGLE_4     = 4 // right angle at the bottom left corner
+	SCA_SHAPE_RHOMBUS        = 5
+	SCA_SHAPE_CIRCLE         = 6
+	SCA_SHAPE_RECT_ROUNDED_X = 7 // rectangle with round ends along the x axis
+	SCA_SHAPE_RECT_ROUNDED_Z = 8 // rectangle with round ends along the z axis
+	SCA_SHAPE_CLIMB_UP       = 9
+	SCA_SHAPE_JUMP_DOWN      = 10
+	SCA_SHAPE_SLOPE          = 11
+	SCA_SHAPE_STAIRS         = 12
+	SCA_SHAPE_CLIMB_HIGH     = 13
+	SCA_SHAPE_JUMP_DOWN_HIGH = 14
+	SCA_SHAPE_UNUSED         = 15
+)
+
+// Collision type bits stored in the lower bits of the element type
+const (
+	SCA_COLLISION_PLAYER      = 0x1
+	SCA_COLLISION_ENEMY       = 0x2
+	SCA_COLLISION_BULLET      = 0x4
+	SCA_COLLISION_OBJECT      = 0x8
+	SCA_COLLISION_ENEMY_FLOOR = 0x10
+)
+
+// SCAShapeName maps collision shapes to their names
+var SCAShapeName = map[int]string{
+	SCA_SHAPE_RECTANGLE:      "Rectangle",
+	SCA_SHAPE_TRIANGLE_1:     "Triangle1",
+	SCA_SHAPE_TRIANGLE_2:     "Triangle2",
+	SCA_SHAPE_TRIANGLE_3:     "Triangle3",
+	SCA_SHAPE_TRIANGLE_4:     "Triangle4",
+	SCA_SHAPE_RHOMBUS:        "Rhombus",
+	SCA_SHAPE_CIRCLE:         "Circle",
+	SCA_SHAPE_RECT_ROUNDED_X: "RoundedRectX",
+	SCA_SHAPE_RECT_ROUNDED_Z: "RoundedRectZ",
+	SCA_SHAPE_CLIMB_UP:       "ClimbUp",
+	SCA_SHAPE_JUMP_DOWN:      "JumpDown",
+	SCA_SHAPE_SLOPE:          "Slope",
+	SCA_SHAPE_STAIRS:         "Stairs",
+	SCA_SHAPE_CLIMB_HIGH:     "ClimbHigh",
+	SCA_SHAPE_JUMP_DOWN_HIGH: "JumpDownHigh",
+	SCA_SHAPE_UNUSED:         "Unused",
+}
+
+type SCAHeader struct {
+	CenterX int16 // center of the room
+	CenterZ int16
+	Counts  [5]uint32 // Counts[0] is the number of elements plus one
+}
+
+// SCAElement is a single collision boundary on the floor plan of the room
+type SCAElement struct {
+	X     int16 // top left corner in world coordinates
+	Z     int16
+	Width uint16
+	Depth uint16
+	Id    uint16 // lower 4 bits are the shape
+	Type  uint16 // collision type bits, upper bits are the height
+	Floor uint32 // bit set of the floors where the boundary collides
+}
+
+// SCAOutput represents the parsed output from a collision file
+type SCAOutput struct {
+	Header   SCAHeader
+	Elements []SCAElement
+}
+
+func LoadRDT_SCAStream(fileReader io.ReaderAt, fileLength int64) (*SCAOutput, error) {
+	streamReader := io.NewSectionReader(fileReader, int64(0), fileLength)
+
+	header := SCAHeader{}
+	if err := binary.Read(streamReader, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+
+	elements := make([]SCAElement, 0)
+	if header.Counts[0] > 1 {
+		// The count comes from the file, so it is checked before the elements are allocated
+		maxElements := (fileLength - int64(binary.Size(header))) / int64(binary.Size(SCAElement{}))
+		if int64(header.Counts[0]-1) > maxElements {
+			return nil, fmt.Errorf("collision data has %d elements, but only %d fit into the file", header.Counts[0]-1, maxElements)
+		}
+		elements = make([]SCAElement, header.Counts[0]-1)
+		if err := binary.Read(streamReader, binary.LittleEndian, &elements); err != nil {
+			return nil, err
+		}
+	}
+
+	output := &SCAOutput{
+		Header:   header,
+		Elements: elements,
+	}
+	return output, nil
+}
+
+// Shape returns the shape of the collision boundary
+func (element SCAElement) Shape() int {
+	return int(element.Id & 0xf)
+}
+
+// CollisionType returns the bits selecting what collides with the boundary
+func (element SCAElement) CollisionType() int {
+	return int(element.Type & 0x1f)
+}
+
+// Height returns the height level of the boundary, used by slopes and stairs
+func (element SCAElement) Height() int {
+	return int(element.Type >> 6)
+}
+
+// OnFloor returns true if the boundary collides on the floor
+func (element SCAElement) OnFloor(floor int) bool {
+	return floor >= 0 && floor < 32 && element.Floor&(1<<uint(floor)) != 0
+}
+
+// ConvertCollisionToString prints a table of every collision boundary in the room
+func ConvertCollisionToString(scaOutput *SCAOutput) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Center: [%d, %d]\n", scaOutput.Header.CenterX, scaOutput.Header.CenterZ))
+
+	writer := tabwriter.NewWriter(&builder, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "Index\tShape\tX\tZ\tWidth\tDepth\tCollision\tHeight\tFloor")
+	for i, element := range scaOutput.Elements {
+		shapeName, exists := SCAShapeName[element.Shape()]
+		if !exists {
+			shapeName = fmt.Sprintf("%d", element.Shape())
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%d\t%d\t%d\t%d\t0x%02x\t%d\t0x%x\n",
+			i, shapeName, element.X, element.Z, element.Width, element.Depth,
+			element.CollisionType(), element.Height(), element.Floor)
+	}
+	writer.Flush()
+	return builder.String()
+}
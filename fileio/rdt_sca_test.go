@@ -0,0 +1,55 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// scaData builds collision data with the count in the header and the elements after it
+func scaData(count uint32, elements ...SCAElement) []byte {
+	var buffer bytes.Buffer
+	binary.Write(&buffer, binary.LittleEndian, SCAHeader{CenterX: -100, CenterZ: 200, Counts: [5]uint32{count}})
+	binary.Write(&buffer, binary.LittleEndian, elements)
+	return buffer.Bytes()
+}
+
+func TestLoadRDT_SCAStream(t *testing.T) {
+	wall := SCAElement{X: 10, Z: 20, Width: 300, Depth: 400, Id: SCA_SHAPE_CIRCLE, Type: SCA_COLLISION_PLAYER}
+	tests := []struct {
+		name         string
+		data         []byte
+		wantElements int
+		wantErr      string
+	}{
+		{"no elements", scaData(0), 0, ""},
+		{"count of one has no elements", scaData(1), 0, ""},
+		{"two elements", scaData(3, wall, wall), 2, ""},
+		{"truncated header", scaData(3, wall, wall)[:10], 0, "EOF"},
+		{"more elements than fit", scaData(4, wall, wall), 0, "collision data has 3 elements, but only 2 fit into the file"},
+		{"huge count", scaData(0xffffffff), 0, "but only 0 fit into the file"},
+		{"truncated element", scaData(3, wall, wall)[:len(scaData(3, wall, wall))-1], 0, "but only 1 fit into the file"},
+	}
+	for _, test := range tests {
+		output, err := LoadRDT_SCAStream(bytes.NewReader(test.data), int64(len(test.data)))
+		if test.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("%s: got error %v, want %q", test.name, err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if len(output.Elements) != test.wantElements {
+			t.Errorf("%s: got %d elements, want %d", test.name, len(output.Elements), test.wantElements)
+		}
+		for _, element := range output.Elements {
+			if element != wall {
+				t.Errorf("%s: got element %+v, want %+v", test.name, element, wall)
+			}
+		}
+	}
+}
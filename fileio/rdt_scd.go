@@ -8,27 +8,18 @@ import (
 	"io"
 )
 
-func LoadRDT_SCDStream(fileReader io.ReaderAt, fileLength int64) (*SCDOutput, error) {
-	streamReader := io.NewSectionReader(fileReader, int64(0), fileLength)
-	firstOffset := uint16(0)
-	if err := binary.Read(streamReader, binary.LittleEndian, &firstOffset); err != nil {
+// LoadRDT_SCDStream parses the function offset table and the instructions of every function.
+// A function that cannot be parsed is cut off at the broken instruction and parsing continues
+// with the next function, unless StrictParsing is set.
+func LoadRDT_SCDStream(fileReader io.ReaderAt, fileLength int64, opcodes *OpcodeSet) (*SCDOutput, error) {
+	output := newSCDOutput()
+	functionOffsets, err := readFunctionOffsets(fileReader, fileLength, &output.Diagnostics)
+	if err != nil {
 		return nil, err
 	}
 
-	functionOffsets := make([]uint16, 0)
-	functionOffsets = append(functionOffsets, firstOffset)
-	for i := 2; i < int(firstOffset); i += 2 {
-		nextOffset := uint16(0)
-		if err := binary.Read(streamReader, binary.LittleEndian, &nextOffset); err != nil {
-			return nil, err
-		}
-		functionOffsets = append(functionOffsets, nextOffset)
-	}
-
 	programCounter := 0
-	scriptData := ScriptFunction{}
-	scriptData.Instructions = make(map[int][]byte)
-	scriptData.StartProgramCounter = make([]int, 0)
+	scriptData := &output.ScriptData
 	for functionNum := 0; functionNum < len(functionOffsets); functionNum++ {
 		scriptData.StartProgramCounter = append(scriptData.StartProgramCounter, programCounter)
 
@@ -39,42 +30,185 @@ func LoadRDT_SCDStream(fileReader io.ReaderAt, fileLength int64) (*SCDOutput, er
 			functionLength = fileLength - int64(functionOffsets[functionNum])
 		}
 
-		streamReader = io.NewSectionReader(fileReader, int64(functionOffsets[functionNum]), functionLength)
-		for lineNum := 0; lineNum < int(functionLength); lineNum++ {
-			opcode := byte(0)
-			if err := binary.Read(streamReader, binary.LittleEndian, &opcode); err != nil {
+		streamReader := io.NewSectionReader(fileReader, int64(functionOffsets[functionNum]), functionLength)
+		problem := loadSCDFunction(streamReader, functionLength, scriptData, &programCounter, opcodes)
+		if problem != nil {
+			problem.Function = functionNum
+			problem.Offset += int64(functionOffsets[functionNum])
+			if err := reportProblem(&output.Diagnostics, problem); err != nil {
 				return nil, err
 			}
+		}
+	}
+	reportResizedOpcodes(output, opcodes, func(functionNum int) int64 { return int64(functionOffsets[functionNum]) })
+	return output, nil
+}
 
-			byteSize, exists := InstructionSize[opcode]
-			if !exists {
-				fmt.Println("Unknown opcode:", opcode)
-			}
+// scriptBlockHeaderSize is the size of the length at the start of a script block
+const scriptBlockHeaderSize = 2
 
-			scriptLine, err := generateScriptLine(streamReader, byteSize, opcode)
-			if err != nil {
-				return nil, err
-			}
-			scriptData.Instructions[programCounter] = scriptLine
+// LoadRDT_SCDBlockStream parses a script that is one block of instructions after its length,
+// as in the rooms of the original Resident Evil. The block is read as a single function
+// that ends with the block instead of EvtEnd.
+func LoadRDT_SCDBlockStream(fileReader io.ReaderAt, fileLength int64, opcodes *OpcodeSet) (*SCDOutput, error) {
+	output := newSCDOutput()
+	blockLength := uint16(0)
+	streamReader := io.NewSectionReader(fileReader, int64(0), fileLength)
+	if err := binary.Read(streamReader, binary.LittleEndian, &blockLength); err != nil {
+		return nil, reportProblem(&output.Diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Function: NoFunction,
+			Message:  fmt.Sprintf("cannot read the length of the script: %v", err),
+		})
+	}
+	functionLength := int64(blockLength)
+	if scriptBlockHeaderSize+functionLength > fileLength {
+		functionLength = fileLength - scriptBlockHeaderSize
+		err := reportProblem(&output.Diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Function: NoFunction,
+			Message:  fmt.Sprintf("script has %d bytes, but only %d bytes are left in the file", blockLength, functionLength),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-			// Sleep contains sleep and sleeping commands
-			if opcode == OP_SLEEP {
-				scriptData.Instructions[programCounter+1] = scriptData.Instructions[programCounter][1:]
-			}
+	programCounter := 0
+	scriptData := &output.ScriptData
+	scriptData.StartProgramCounter = append(scriptData.StartProgramCounter, programCounter)
+	functionReader := io.NewSectionReader(fileReader, scriptBlockHeaderSize, functionLength)
+	problem := loadSCDFunction(functionReader, functionLength, scriptData, &programCounter, opcodes)
+	if problem != nil && problem.Severity == SeverityError {
+		problem.Function = 0
+		problem.Offset += scriptBlockHeaderSize
+		if err := reportProblem(&output.Diagnostics, problem); err != nil {
+			return nil, err
+		}
+	}
+	reportResizedOpcodes(output, opcodes, func(int) int64 { return scriptBlockHeaderSize })
+	return output, nil
+}
 
-			programCounter += byteSize
+// reportResizedOpcodes warns about every instruction whose opcode has another size than the RE2 opcode
+// with the same number in a spec file. These instructions are shown, but are not read as the RE2 opcode.
+// functionOffset returns the offset of the first instruction of a function in the script.
+func reportResizedOpcodes(output *SCDOutput, opcodes *OpcodeSet, functionOffset func(functionNum int) int64) {
+	script := output.ScriptData
+	for _, instruction := range script.Program {
+		opcode := instruction.Bytes()[0]
+		builtinSize, exists := opcodes.resized[opcode]
+		if !exists {
+			continue
+		}
+		functionNum := script.FunctionIndex(instruction.Offset())
+		output.Diagnostics = append(output.Diagnostics, &Diagnostic{
+			Severity: SeverityWarning,
+			Function: functionNum,
+			Offset:   functionOffset(functionNum) + int64(instruction.Offset()-script.StartProgramCounter[functionNum]),
+			Message: fmt.Sprintf("%s has %d bytes, but %s has %d bytes in RE2, so it is not read as %s",
+				instruction.Name(), instruction.Length(), FunctionName[opcode], builtinSize, FunctionName[opcode]),
+		})
+	}
+}
+
+// readFunctionOffsets reads the offset table at the start of a script. The first offset is also
+// the size of the table. Offsets outside of the script end the table.
+func readFunctionOffsets(fileReader io.ReaderAt, fileLength int64, diagnostics *[]*Diagnostic) ([]uint16, error) {
+	streamReader := io.NewSectionReader(fileReader, int64(0), fileLength)
+	functionOffsets := make([]uint16, 0)
+	for i := 0; i == 0 || i < int(functionOffsets[0]); i += 2 {
+		nextOffset := uint16(0)
+		if err := binary.Read(streamReader, binary.LittleEndian, &nextOffset); err != nil {
+			return functionOffsets, reportProblem(diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Function: NoFunction,
+				Offset:   int64(i),
+				Message:  fmt.Sprintf("cannot read the function offset table: %v", err),
+			})
+		}
+
+		var message string
+		switch {
+		case i == 0 && (nextOffset < 2 || nextOffset%2 != 0):
+			message = fmt.Sprintf("invalid size %d of the function offset table", nextOffset)
+		case int64(nextOffset) >= fileLength:
+			message = fmt.Sprintf("function %d starts at 0x%x after the end of the script", len(functionOffsets), nextOffset)
+		case i > 0 && nextOffset < functionOffsets[len(functionOffsets)-1]:
+			message = fmt.Sprintf("function %d starts at 0x%x before the previous function", len(functionOffsets), nextOffset)
+		}
+		if message != "" {
+			return functionOffsets, reportProblem(diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Function: NoFunction,
+				Offset:   int64(i),
+				Message:  message + ", the remaining functions are skipped",
+			})
+		}
+		functionOffsets = append(functionOffsets, nextOffset)
+	}
+	return functionOffsets, nil
+}
+
+// loadSCDFunction parses the instructions of a function until EvtEnd.
+// It returns the problem that ended the function early with the offset within the function.
+func loadSCDFunction(streamReader *io.SectionReader, functionLength int64, scriptData *ScriptFunction, programCounter *int, opcodes *OpcodeSet) *Diagnostic {
+	position := int64(0)
+	for position < functionLength {
+		opcode := byte(0)
+		if err := binary.Read(streamReader, binary.LittleEndian, &opcode); err != nil {
+			return &Diagnostic{Severity: SeverityError, Offset: position, Message: fmt.Sprintf("cannot read opcode: %v", err)}
+		}
 
-			// return
-			if opcode == OP_EVT_END {
-				break
+		byteSize, exists := opcodes.InstructionSize(opcode)
+		if !exists {
+			return &Diagnostic{Severity: SeverityError, Offset: position, Message: fmt.Sprintf("unknown opcode 0x%02x", opcode)}
+		}
+		if position+int64(byteSize) > functionLength {
+			return &Diagnostic{
+				Severity: SeverityError,
+				Offset:   position,
+				Message:  fmt.Sprintf("%s has %d bytes, but only %d bytes are left in the function", opcodes.FunctionName(opcode), byteSize, functionLength-position),
 			}
 		}
+
+		scriptLine, err := generateScriptLine(streamReader, byteSize, opcode)
+		if err != nil {
+			return &Diagnostic{Severity: SeverityError, Offset: position, Message: err.Error()}
+		}
+		instruction, err := opcodes.DecodeInstruction(*programCounter, scriptLine)
+		if err != nil {
+			return &Diagnostic{Severity: SeverityError, Offset: position, Message: err.Error()}
+		}
+		scriptData.Instructions[*programCounter] = scriptLine
+		scriptData.Program = append(scriptData.Program, instruction)
+
+		// Sleep contains sleep and sleeping commands
+		if instruction.Opcode() == OP_SLEEP {
+			scriptData.Instructions[*programCounter+1] = scriptData.Instructions[*programCounter][1:]
+		}
+
+		*programCounter += byteSize
+		position += int64(byteSize)
+
+		// return
+		if instruction.Opcode() == OP_EVT_END {
+			return nil
+		}
 	}
+	return &Diagnostic{Severity: SeverityWarning, Offset: position, Message: "function ends without EvtEnd"}
+}
 
-	output := &SCDOutput{
-		ScriptData: scriptData,
+// newSCDOutput returns a script without functions
+func newSCDOutput() *SCDOutput {
+	return &SCDOutput{
+		ScriptData: ScriptFunction{
+			Instructions:        make(map[int][]byte),
+			StartProgramCounter: make([]int, 0),
+			Program:             make([]Instruction, 0),
+		},
+		Diagnostics: make([]*Diagnostic, 0),
 	}
-	return output, nil
 }
 
 func generateScriptLine(streamReader *io.SectionReader, totalByteSize int, opcode byte) ([]byte, error) {
@@ -0,0 +1,280 @@
+package fileio
+
+// Rebuilds a room file with replaced script data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// Indexes of the sections in RDTOffsets
+const (
+	RDT_SECTION_OTA             = 5
+	RDT_SECTION_COLLISION       = 6
+	RDT_SECTION_CAMERA_POSITION = 7
+	RDT_SECTION_CAMERA_SWITCHES = 8
+	RDT_SECTION_MODELS          = 10
+	RDT_SECTION_LANG1           = 13
+	RDT_SECTION_LANG2           = 14
+	RDT_SECTION_INIT_SCRIPT     = 16
+	RDT_SECTION_EXECUTE_SCRIPT  = 17
+)
+
+const (
+	rdtHeaderSize     = 8
+	rdtNumOffsets     = 23
+	rdtSectionAlign   = 4
+	ridCameraSize     = 32 // size of RIDCamera
+	ridMaskOffsetSize = 4  // MaskOffset is the last field of RIDCamera
+	modelEntrySize    = 8  // offsets of the TIM texture and the MD1 mesh of a model
+)
+
+// RDTPatch holds the new functions of the scripts of a room file.
+// Functions that are not replaced keep their original bytecode.
+type RDTPatch struct {
+	InitFunctions [][]byte       // bytecode of every function of the new init script, nil to keep the init script
+	RoomFunctions map[int][]byte // bytecode of the replaced functions of the room script by function index
+}
+
+// BuildSCD creates the .scd data of a script from the bytecode of its functions.
+// The data starts with a table of the offsets of every function.
+func BuildSCD(functions [][]byte) []byte {
+	var buffer bytes.Buffer
+	offset := 2 * len(functions)
+	for _, function := range functions {
+		binary.Write(&buffer, binary.LittleEndian, uint16(offset))
+		offset += len(function)
+	}
+	for _, function := range functions {
+		buffer.Write(function)
+	}
+	return buffer.Bytes()
+}
+
+// SplitInitFunctions splits the instructions of init.scd into the functions of the init script.
+// init.scd shows the functions one after another, and the parser ends every function with its first EvtEnd.
+func (opcodes *OpcodeSet) SplitInitFunctions(instructions [][]byte) [][]byte {
+	functions := make([][]byte, 0)
+	function := make([]byte, 0)
+	for _, lineBytes := range instructions {
+		function = append(function, lineBytes...)
+		if opcodes.CanonicalOpcode(lineBytes[0]) == OP_EVT_END {
+			functions = append(functions, function)
+			function = make([]byte, 0)
+		}
+	}
+	if len(function) > 0 || len(functions) == 0 {
+		functions = append(functions, function)
+	}
+	return functions
+}
+
+// PatchRDTFile writes a copy of a room file with the functions of the patch replaced
+func PatchRDTFile(inFilename string, outFilename string, patch RDTPatch) error {
+	data, err := os.ReadFile(inFilename)
+	if err != nil {
+		return fmt.Errorf("failed to read RDT file %s: %w", inFilename, err)
+	}
+
+	output, err := PatchRDT(data, patch)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outFilename, output, 0644)
+}
+
+// PatchRDT returns a copy of the room data with the functions of the patch replaced.
+// The functions that are kept are copied from the original scripts, including any bytes
+// after their EvtEnd. Rooms whose scripts do not parse cleanly are not patched, because
+// the functions cannot be told apart reliably.
+//
+// A script that fits into the space of the old section is written in place. A larger script
+// moves the following sections back as described in replaceRDTSection.
+func PatchRDT(data []byte, patch RDTPatch) ([]byte, error) {
+	original, err := LoadRDT(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	if !original.Game.roomLayout().patchable() {
+		return nil, fmt.Errorf("rooms of %s cannot be patched", original.Game.Name)
+	}
+	for _, diagnostic := range original.Diagnostics {
+		if diagnostic.Section == SectionInitScript || diagnostic.Section == SectionRoomScript {
+			return nil, fmt.Errorf("the scripts of the room cannot be patched: %w", diagnostic)
+		}
+	}
+
+	output := append([]byte(nil), data...)
+	if patch.InitFunctions != nil {
+		if output, err = replaceRDTSection(output, RDT_SECTION_INIT_SCRIPT, BuildSCD(patch.InitFunctions)); err != nil {
+			return nil, fmt.Errorf("failed to replace init script: %w", err)
+		}
+	}
+	if len(patch.RoomFunctions) > 0 {
+		functions, err := scriptFunctions(output, RDT_SECTION_EXECUTE_SCRIPT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read room script: %w", err)
+		}
+		for functionNum, function := range patch.RoomFunctions {
+			if functionNum < 0 || functionNum >= len(functions) {
+				return nil, fmt.Errorf("room script has no function %d, it has %d functions", functionNum, len(functions))
+			}
+			functions[functionNum] = function
+		}
+		if output, err = replaceRDTSection(output, RDT_SECTION_EXECUTE_SCRIPT, BuildSCD(functions)); err != nil {
+			return nil, fmt.Errorf("failed to replace room script: %w", err)
+		}
+	}
+
+	// The patched room has to be readable again, without problems in the scripts
+	// and without problems in other sections that the original room did not have
+	rdtOutput, err := LoadRDT(bytes.NewReader(output), int64(len(output)))
+	if err == nil {
+		err = patchProblem(original.Diagnostics, rdtOutput.Diagnostics)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("patched room is invalid: %w", err)
+	}
+	return output, nil
+}
+
+// patchProblem returns the first problem of the scripts of the patched room, or the first error
+// of the patched room if it has more errors than the original room
+func patchProblem(original []*Diagnostic, patched []*Diagnostic) error {
+	for _, diagnostic := range patched {
+		if diagnostic.Section == SectionInitScript || diagnostic.Section == SectionRoomScript {
+			return diagnostic
+		}
+	}
+	originalErrors, _ := CountDiagnostics(original)
+	patchedErrors, _ := CountDiagnostics(patched)
+	if patchedErrors > originalErrors {
+		return FirstError(patched)
+	}
+	return nil
+}
+
+// scriptFunctions returns the bytecode of every function of the script section with the index
+// in RDTOffsets. A function runs from its offset in the function table up to the next function,
+// or up to the next section for the last function.
+func scriptFunctions(data []byte, section int) ([][]byte, error) {
+	offsets, err := readRDTOffsets(bytes.NewReader(data), int64(len(data)), re2Layout)
+	if err != nil {
+		return nil, err
+	}
+	start := offsets[section]
+	if start == 0 || int(start) >= len(data) {
+		return nil, fmt.Errorf("section %d has an invalid offset %d", section, start)
+	}
+	script := data[start:sectionEnd([rdtNumOffsets]uint32(offsets), start, uint32(len(data)))]
+
+	diagnostics := make([]*Diagnostic, 0)
+	functionOffsets, err := readFunctionOffsets(bytes.NewReader(script), int64(len(script)), &diagnostics)
+	if err == nil {
+		err = FirstError(diagnostics)
+	}
+	if err != nil {
+		return nil, err
+	}
+	functions := make([][]byte, len(functionOffsets))
+	for functionNum, functionOffset := range functionOffsets {
+		end := len(script)
+		if functionNum+1 < len(functionOffsets) {
+			end = int(functionOffsets[functionNum+1])
+		}
+		functions[functionNum] = append([]byte(nil), script[functionOffset:end]...)
+	}
+	return functions, nil
+}
+
+// replaceRDTSection replaces the data of the section with the index in RDTOffsets.
+// If the new data does not fit into the old section, the following sections move back and
+// the absolute offsets that point behind the section are updated: the offsets in RDTOffsets,
+// the mask offsets of the cameras in the .rid data and the TIM and MD1 offsets of the model table.
+// The OTA data is not understood and may hold more absolute offsets, so it is never moved.
+func replaceRDTSection(data []byte, section int, sectionData []byte) ([]byte, error) {
+	header := RDTHeader{}
+	offsets := [rdtNumOffsets]uint32{}
+	reader := bytes.NewReader(data)
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &offsets); err != nil {
+		return nil, err
+	}
+
+	start := offsets[section]
+	if start == 0 || int(start) > len(data) {
+		return nil, fmt.Errorf("section %d has an invalid offset %d", section, start)
+	}
+	end := sectionEnd(offsets, start, uint32(len(data)))
+
+	// Use the free space of the old section if the new data fits
+	if len(sectionData) <= int(end-start) {
+		copy(data[start:end], sectionData)
+		clear(data[int(start)+len(sectionData) : end])
+		return data, nil
+	}
+	if offsets[RDT_SECTION_OTA] >= end {
+		return nil, fmt.Errorf("new data has %d bytes, but only %d bytes fit before the OTA data, which cannot be moved",
+			len(sectionData), end-start)
+	}
+
+	paddedLength := (len(sectionData) + rdtSectionAlign - 1) / rdtSectionAlign * rdtSectionAlign
+	delta := uint32(paddedLength) - (end - start)
+
+	output := make([]byte, 0, len(data)+int(delta))
+	output = append(output, data[:start]...)
+	output = append(output, sectionData...)
+	output = append(output, make([]byte, paddedLength-len(sectionData))...)
+	output = append(output, data[end:]...)
+
+	for i := range offsets {
+		if offsets[i] >= end {
+			offsets[i] += delta
+		}
+	}
+	for i, offset := range offsets {
+		binary.LittleEndian.PutUint32(output[rdtHeaderSize+i*4:], offset)
+	}
+
+	// The camera positions point to the masks of each camera with absolute offsets
+	cameraStart := int(offsets[RDT_SECTION_CAMERA_POSITION])
+	if cameraStart != 0 && cameraStart+int(header.NumCameras)*ridCameraSize <= len(output) {
+		for i := 0; i < int(header.NumCameras); i++ {
+			position := cameraStart + (i+1)*ridCameraSize - ridMaskOffsetSize
+			relocateOffset(output, position, end, delta, len(data))
+		}
+	}
+
+	// The model table points to the texture and the mesh of each model with absolute offsets
+	modelStart := int(offsets[RDT_SECTION_MODELS])
+	if modelStart != 0 && modelStart+int(header.NumModels)*modelEntrySize <= len(output) {
+		for position := modelStart; position < modelStart+int(header.NumModels)*modelEntrySize; position += 4 {
+			relocateOffset(output, position, end, delta, len(data))
+		}
+	}
+	return output, nil
+}
+
+// relocateOffset moves the absolute offset at the position back by delta if it points
+// behind the end of the replaced section and into the old file
+func relocateOffset(output []byte, position int, end uint32, delta uint32, oldLength int) {
+	offset := binary.LittleEndian.Uint32(output[position:])
+	if offset >= end && offset < uint32(oldLength) {
+		binary.LittleEndian.PutUint32(output[position:], offset+delta)
+	}
+}
+
+// sectionEnd returns the start of the next section, or the end of the file for the last section
+func sectionEnd(offsets [rdtNumOffsets]uint32, start uint32, fileLength uint32) uint32 {
+	end := fileLength
+	for _, offset := range offsets {
+		if offset > start && offset < end {
+			end = offset
+		}
+	}
+	return end
+}
@@ -0,0 +1,107 @@
+package fileio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// Offsets of the sections of the room built by testRoom
+const (
+	testCameraOffset     = rdtHeaderSize + rdtNumOffsets*4
+	testSwitchesOffset   = testCameraOffset + ridCameraSize
+	testCollisionOffset  = testSwitchesOffset + 20
+	testInitScriptOffset = testCollisionOffset + 24
+	testRoomScriptOffset = testInitScriptOffset + 4
+	testModelsOffset     = testRoomScriptOffset + 8
+	testTextureOffset    = testModelsOffset + modelEntrySize
+	testMeshOffset       = testTextureOffset + 4
+	testMaskOffset       = testMeshOffset + 4
+	testRoomLength       = testMaskOffset + 4
+)
+
+// testRoom builds a room with one camera, one model, an init script with one function
+// and a room script with two functions. The first room function has a byte after its EvtEnd.
+func testRoom() []byte {
+	data := make([]byte, testRoomLength)
+	data[1] = 1 // NumCameras
+	data[2] = 1 // NumModels
+
+	offsets := map[int]uint32{
+		RDT_SECTION_COLLISION:       testCollisionOffset,
+		RDT_SECTION_CAMERA_POSITION: testCameraOffset,
+		RDT_SECTION_CAMERA_SWITCHES: testSwitchesOffset,
+		RDT_SECTION_MODELS:          testModelsOffset,
+		RDT_SECTION_INIT_SCRIPT:     testInitScriptOffset,
+		RDT_SECTION_EXECUTE_SCRIPT:  testRoomScriptOffset,
+	}
+	for section, offset := range offsets {
+		binary.LittleEndian.PutUint32(data[rdtHeaderSize+section*4:], offset)
+	}
+
+	binary.LittleEndian.PutUint32(data[testSwitchesOffset-ridMaskOffsetSize:], testMaskOffset)
+	binary.LittleEndian.PutUint16(data[testSwitchesOffset:], rvdEndMarker)
+	binary.LittleEndian.PutUint32(data[testCollisionOffset+4:], 1) // no collision boundaries
+	copy(data[testInitScriptOffset:], BuildSCD([][]byte{{OP_EVT_END}}))
+	copy(data[testRoomScriptOffset:], BuildSCD([][]byte{{OP_EVT_END, 0xaa}, {OP_EVT_END}}))
+	binary.LittleEndian.PutUint32(data[testModelsOffset:], testTextureOffset)
+	binary.LittleEndian.PutUint32(data[testModelsOffset+4:], testMeshOffset)
+	return data
+}
+
+func TestPatchRDTWithoutChanges(t *testing.T) {
+	data := testRoom()
+	patches := map[string]RDTPatch{
+		"empty patch": {},
+		"same functions": {
+			InitFunctions: [][]byte{{OP_EVT_END}},
+			RoomFunctions: map[int][]byte{0: {OP_EVT_END, 0xaa}, 1: {OP_EVT_END}},
+		},
+	}
+	for name, patch := range patches {
+		output, err := PatchRDT(append([]byte(nil), data...), patch)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(output, data) {
+			t.Errorf("%s: patched room differs from the original room\ngot  % x\nwant % x", name, output, data)
+		}
+	}
+}
+
+func TestPatchRDTMovesSections(t *testing.T) {
+	function := []byte{0, 0, 0, 0, 0, 0, OP_EVT_END}
+	output, err := PatchRDT(testRoom(), RDTPatch{RoomFunctions: map[int][]byte{1: function}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The script grows from 7 to 13 bytes, which is padded to 16 bytes
+	const delta = 8
+	if len(output) != testRoomLength+delta {
+		t.Fatalf("patched room has %d bytes, want %d", len(output), testRoomLength+delta)
+	}
+	offsets := map[string][2]int{
+		"model table":   {rdtHeaderSize + RDT_SECTION_MODELS*4, testModelsOffset + delta},
+		"camera mask":   {testSwitchesOffset - ridMaskOffsetSize, testMaskOffset + delta},
+		"model texture": {testModelsOffset + delta, testTextureOffset + delta},
+		"model mesh":    {testModelsOffset + delta + 4, testMeshOffset + delta},
+		"room script":   {rdtHeaderSize + RDT_SECTION_EXECUTE_SCRIPT*4, testRoomScriptOffset},
+	}
+	for name, offset := range offsets {
+		if got := binary.LittleEndian.Uint32(output[offset[0]:]); got != uint32(offset[1]) {
+			t.Errorf("%s offset is 0x%x, want 0x%x", name, got, offset[1])
+		}
+	}
+
+	functions, err := scriptFunctions(output, RDT_SECTION_EXECUTE_SCRIPT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(functions[0], []byte{OP_EVT_END, 0xaa}) {
+		t.Errorf("function 0 is % x, want the original bytes", functions[0])
+	}
+	if !bytes.HasPrefix(functions[1], function) {
+		t.Errorf("function 1 is % x, want % x", functions[1], function)
+	}
+}
@@ -0,0 +1,221 @@
+package fileio
+
+// Assembles pseudocode back into script bytecode.
+// The pseudocode has the same syntax as ConvertScriptInstructionsToCode:
+// one Name(Field=value, ...); instruction per line.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// opcodeByName maps the names of the RE2 opcodes in FunctionName back to their opcodes
+var opcodeByName = func() map[string]byte {
+	opcodes := make(map[string]byte, len(FunctionName))
+	for opcode, name := range FunctionName {
+		opcodes[name] = opcode
+	}
+	return opcodes
+}()
+
+// blockCommentPattern matches the comments that GetRoomOpcodeSignature adds after parameters
+var blockCommentPattern = regexp.MustCompile(`/\*.*?\*/`)
+
+// AssembleScript converts pseudocode with one instruction per line into the bytecode of every instruction.
+// Empty lines and // comments are skipped. The Sleeping entry that the viewer shows after
+// every Sleep instruction is part of the Sleep bytecode, so it is not assembled a second time.
+func (opcodes *OpcodeSet) AssembleScript(code string) ([][]byte, error) {
+	instructions := make([][]byte, 0)
+	expectSleeping := false
+	scanner := bufio.NewScanner(strings.NewReader(code))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		lineBytes, err := opcodes.AssembleInstruction(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		if expectSleeping && bytes.Equal(instructions[len(instructions)-1][1:], lineBytes) {
+			expectSleeping = false
+			continue
+		}
+		instructions = append(instructions, lineBytes)
+		expectSleeping = opcodes.CanonicalOpcode(lineBytes[0]) == OP_SLEEP
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return instructions, nil
+}
+
+// AssembleInstruction converts a single line of pseudocode into the bytecode of the instruction.
+// Parameters are either named fields of the ScriptInstr struct of the opcode, or a list of
+// raw byte values as printed for opcodes without a struct.
+func (opcodes *OpcodeSet) AssembleInstruction(line string) ([]byte, error) {
+	line = strings.TrimSpace(blockCommentPattern.ReplaceAllString(line, ""))
+	line = strings.TrimSuffix(line, ";")
+
+	open := strings.Index(line, "(")
+	if open < 0 || !strings.HasSuffix(line, ")") {
+		return nil, fmt.Errorf("expected Name(parameters) but got %q", line)
+	}
+	name := strings.TrimSpace(line[:open])
+	opcode, exists := opcodes.byName[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown instruction %s", name)
+	}
+
+	params := splitParams(line[open+1 : len(line)-1])
+	if len(params) > 0 && !strings.Contains(params[0], "=") {
+		return opcodes.assembleRawParams(opcode, params)
+	}
+	return opcodes.assembleNamedParams(opcode, params)
+}
+
+// splitParams splits the parameter list at the commas that are not inside an array
+func splitParams(paramList string) []string {
+	params := make([]string, 0)
+	depth := 0
+	start := 0
+	for i, char := range paramList {
+		switch char {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				params = append(params, strings.TrimSpace(paramList[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(paramList[start:]); last != "" {
+		params = append(params, last)
+	}
+	return params
+}
+
+// assembleRawParams assembles an instruction whose parameters are the bytes after the opcode
+func (opcodes *OpcodeSet) assembleRawParams(opcode byte, params []string) ([]byte, error) {
+	if len(params) != opcodes.sizes[opcode]-1 {
+		return nil, fmt.Errorf("%s has %d bytes of parameters but got %d", opcodes.names[opcode], opcodes.sizes[opcode]-1, len(params))
+	}
+	lineBytes := []byte{opcode}
+	for _, param := range params {
+		value, err := strconv.ParseInt(param, 0, 64)
+		if err != nil || value < 0 || value > 0xff {
+			return nil, fmt.Errorf("%s: invalid byte %q", opcodes.names[opcode], param)
+		}
+		lineBytes = append(lineBytes, byte(value))
+	}
+	return lineBytes, nil
+}
+
+// assembleNamedParams fills the ScriptInstr struct of the opcode and writes it as bytecode.
+// Opcodes from a spec file are written with the fields of the spec, or with the struct of the
+// RE2 opcode with the same meaning if the spec has no fields.
+func (opcodes *OpcodeSet) assembleNamedParams(opcode byte, params []string) ([]byte, error) {
+	if opcodeSpec, exists := opcodes.specs[opcode]; exists && (opcodeSpec.Builtin == "" || len(opcodeSpec.Fields) > 0) {
+		return opcodeSpec.assemble(params)
+	}
+
+	decoder, exists := opcodes.decoders[opcode]
+	if !exists {
+		return nil, fmt.Errorf("%s has no instruction struct", opcodes.names[opcode])
+	}
+
+	// Decoding an empty instruction returns the zero value of the struct of the opcode
+	emptyLine := make([]byte, opcodes.sizes[opcode])
+	emptyLine[0] = opcode
+	zero, err := decoder(emptyLine)
+	if err != nil {
+		return nil, err
+	}
+	instruction := reflect.New(reflect.TypeOf(zero)).Elem()
+	instruction.Field(0).SetUint(uint64(opcode))
+
+	for _, param := range params {
+		fieldName, value, found := strings.Cut(param, "=")
+		if !found {
+			return nil, fmt.Errorf("%s: expected Field=value but got %q", opcodes.names[opcode], param)
+		}
+		fieldName = strings.TrimSpace(fieldName)
+		field := instruction.FieldByName(fieldName)
+		if !field.IsValid() || fieldName == "Opcode" {
+			return nil, fmt.Errorf("%s has no field %s", opcodes.names[opcode], fieldName)
+		}
+		if err := setFieldValue(field, strings.TrimSpace(value)); err != nil {
+			return nil, fmt.Errorf("%s: field %s: %w", opcodes.names[opcode], fieldName, err)
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := binary.Write(&buffer, binary.LittleEndian, instruction.Interface()); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// setFieldValue parses a number or an array of numbers into the struct field
+func setFieldValue(field reflect.Value, value string) error {
+	if field.Kind() != reflect.Array {
+		return setIntValue(field, value)
+	}
+
+	if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+		return fmt.Errorf("expected an array but got %q", value)
+	}
+	elements := splitParams(value[1 : len(value)-1])
+	if len(elements) != field.Len() {
+		return fmt.Errorf("expected %d values but got %d", field.Len(), len(elements))
+	}
+	for i, element := range elements {
+		if err := setIntValue(field.Index(i), element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setIntValue parses a number into an integer field.
+// Both signed and unsigned values are accepted as long as they fit into the bits of the field.
+func setIntValue(field reflect.Value, value string) error {
+	bits := field.Type().Bits()
+	number, err := parseFieldNumber(value, bits)
+	if err != nil {
+		return err
+	}
+
+	switch field.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		field.SetInt(int64(uint64(number)<<(64-bits)) >> (64 - bits))
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		field.SetUint(uint64(number) & (1<<bits - 1))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// parseFieldNumber parses a number that has to fit into a signed or unsigned field with the number of bits
+func parseFieldNumber(value string, bits int) (int64, error) {
+	number, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", value)
+	}
+	if number < -(1<<(bits-1)) || number >= 1<<bits {
+		return 0, fmt.Errorf("%d does not fit into %d bits", number, bits)
+	}
+	return number, nil
+}
@@ -0,0 +1,43 @@
+package fileio
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+)
+
+// TestAssembleDump assembles the pseudocode of an instruction of every opcode and compares
+// the bytecode with the original instruction
+func TestAssembleDump(t *testing.T) {
+	opcodes := make([]int, 0, len(InstructionSize))
+	for opcode := range InstructionSize {
+		opcodes = append(opcodes, int(opcode))
+	}
+	sort.Ints(opcodes)
+
+	for _, opcode := range opcodes {
+		lineBytes := make([]byte, InstructionSize[byte(opcode)])
+		lineBytes[0] = byte(opcode)
+		for i := 1; i < len(lineBytes); i++ {
+			lineBytes[i] = byte(i)
+		}
+
+		code := ConvertScriptInstructionsToCode([][]byte{lineBytes}, nil)
+		instructions, err := re2OpcodeSet().AssembleScript(code)
+		if err != nil {
+			t.Errorf("%s: %v", code, err)
+			continue
+		}
+		if len(instructions) != 1 || !bytes.Equal(instructions[0], lineBytes) {
+			t.Errorf("%s: assembled % x, want % x", code, instructions, lineBytes)
+		}
+	}
+}
+
+func TestAssembleRawParamsCount(t *testing.T) {
+	for _, line := range []string{"EvtEnd(1);", "NoOp(0, 0);"} {
+		if lineBytes, err := re2OpcodeSet().AssembleInstruction(line); err == nil {
+			t.Errorf("%s: assembled % x, want an error for the number of bytes", line, lineBytes)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package fileio
+
+// Splits parsed script data into one file per function and converts
+// the instructions to hex and pseudocode text
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SplitRDTScripts returns every script function of the room keyed by file name.
+// The init script is stored as init.scd and the room scripts as sub0.scd, sub1.scd, ...
+func SplitRDTScripts(rdtOutput *RDTOutput) map[string][][]byte {
+	scriptFiles := SplitScriptDataIntoFiles(rdtOutput.RoomScriptData)
+	// Add script from init
+	scriptFiles["init.scd"] = ConvertInitialScriptIntoFile(rdtOutput.InitScriptData)
+	return scriptFiles
+}
+
+// ScriptFileProgramCounters returns the program counter of every line of the script files
+// returned by SplitRDTScripts, so that a line can be matched with an instruction of the script
+func ScriptFileProgramCounters(rdtOutput *RDTOutput) map[string][]int {
+	fileProgramCounters := make(map[string][]int)
+	fileProgramCounters["init.scd"] = SortProgramCounters(rdtOutput.InitScriptData.ScriptData.Instructions)
+
+	// Same split as SplitScriptDataIntoFiles
+	roomScript := rdtOutput.RoomScriptData.ScriptData
+	for functionNum := range roomScript.StartProgramCounter {
+		fileProgramCounters[fmt.Sprintf("sub%d.scd", functionNum)] = make([]int, 0)
+	}
+	for _, programCounter := range SortProgramCounters(roomScript.Instructions) {
+		filename := fmt.Sprintf("sub%d.scd", roomScript.FunctionIndex(programCounter))
+		fileProgramCounters[filename] = append(fileProgramCounters[filename], programCounter)
+	}
+	return fileProgramCounters
+}
+
+// SortedScriptFilenames returns the file names of the script files in sorted order
+func SortedScriptFilenames[T any](scriptFiles map[string]T) []string {
+	filenames := make([]string, 0, len(scriptFiles))
+	for filename := range scriptFiles {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	return filenames
+}
+
+func ConvertInitialScriptIntoFile(scriptFile *SCDOutput) [][]byte {
+	programCounters := SortProgramCounters(scriptFile.ScriptData.Instructions)
+
+	fileLines := make([][]byte, 0)
+	for _, programCounter := range programCounters {
+		fileLines = append(fileLines, scriptFile.ScriptData.Instructions[programCounter])
+	}
+	return fileLines
+}
+
+// SplitScriptDataIntoFiles returns the instructions of every function keyed by file name, sub0.scd, sub1.scd, ...
+// A function whose instructions could not be parsed is an empty file, so that the file names match the function indices.
+func SplitScriptDataIntoFiles(scriptFile *SCDOutput) map[string][][]byte {
+	scriptFiles := make(map[string][][]byte)
+	for functionNum := range scriptFile.ScriptData.StartProgramCounter {
+		scriptFiles[fmt.Sprintf("sub%d.scd", functionNum)] = make([][]byte, 0)
+	}
+	for _, programCounter := range SortProgramCounters(scriptFile.ScriptData.Instructions) {
+		filename := fmt.Sprintf("sub%d.scd", scriptFile.ScriptData.FunctionIndex(programCounter))
+		scriptFiles[filename] = append(scriptFiles[filename], scriptFile.ScriptData.Instructions[programCounter])
+	}
+	return scriptFiles
+}
+
+// FunctionIndex returns the index of the function that contains the program counter
+func (script ScriptFunction) FunctionIndex(programCounter int) int {
+	return max(sort.SearchInts(script.StartProgramCounter, programCounter+1)-1, 0)
+}
+
+// ConvertRawScriptInstructionsToString prints every instruction as a line of hex values
+func ConvertRawScriptInstructionsToString(instructions [][]byte) string {
+	var builder strings.Builder
+	for _, lineBytes := range instructions {
+		// print out hex values
+		for i := 0; i < len(lineBytes); i++ {
+			builder.WriteString(fmt.Sprintf("%02x ", lineBytes[i]))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// ConvertScriptInstructionsToCode prints every instruction as a line of pseudocode.
+// The room is used to show the data that parameters refer to and can be nil.
+func ConvertScriptInstructionsToCode(instructions [][]byte, rdtOutput *RDTOutput) string {
+	opcodes := roomOpcodes(rdtOutput)
+	var builder strings.Builder
+	for _, lineBytes := range instructions {
+		builder.WriteString(opcodes.FunctionName(lineBytes[0]))
+		instruction, err := opcodes.DecodeInstruction(0, lineBytes)
+		if err != nil {
+			// Lines with an unknown opcode or another size than the opcode are printed as bytes
+			builder.WriteString("(" + formatDefaultParams(lineBytes) + ");")
+		} else {
+			builder.WriteString(GetRoomOpcodeSignature(instruction, rdtOutput))
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+func SortProgramCounters(instructions map[int][]byte) []int {
+	// sort script commands in order
+	programCounters := make([]int, 0, len(instructions))
+	for counter := range instructions {
+		programCounters = append(programCounters, counter)
+	}
+	sort.Ints(programCounters)
+
+	return programCounters
+}
@@ -0,0 +1,191 @@
+package fileio
+
+// Decoded script instructions
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// Instruction is a script command that was decoded once by the parser.
+// Consumers can type switch on Decoded instead of reading the bytes again.
+type Instruction interface {
+	Opcode() byte  // RE2 opcode with the same meaning, see OpcodeSet.CanonicalOpcode
+	Name() string  // name of the opcode in the game of the room
+	Offset() int   // program counter of the instruction
+	Length() int   // size of the instruction in bytes
+	Bytes() []byte // raw bytecode of the instruction
+	Decoded() any  // struct of the opcode, e.g. ScriptInstrAotSet
+
+	opcodeSet() *OpcodeSet // opcodes the instruction was decoded with
+}
+
+type scriptInstruction struct {
+	opcode    byte
+	offset    int
+	lineBytes []byte
+	decoded   any
+	opcodes   *OpcodeSet
+}
+
+func (instr *scriptInstruction) Opcode() byte {
+	return instr.opcode
+}
+
+func (instr *scriptInstruction) Name() string {
+	return instr.opcodes.FunctionName(instr.lineBytes[0])
+}
+
+func (instr *scriptInstruction) Offset() int {
+	return instr.offset
+}
+
+func (instr *scriptInstruction) Length() int {
+	return len(instr.lineBytes)
+}
+
+func (instr *scriptInstruction) Bytes() []byte {
+	return instr.lineBytes
+}
+
+func (instr *scriptInstruction) Decoded() any {
+	return instr.decoded
+}
+
+func (instr *scriptInstruction) opcodeSet() *OpcodeSet {
+	return instr.opcodes
+}
+
+// instructionDecoder reads the bytes of an instruction into the struct of its opcode
+type instructionDecoder func([]byte) (any, error)
+
+func decodeAs[T any](lineBytes []byte) (any, error) {
+	var instruction T
+	if size := binary.Size(instruction); size != len(lineBytes) {
+		return nil, fmt.Errorf("instruction has %d bytes, but %T has %d bytes", len(lineBytes), instruction, size)
+	}
+	if err := binary.Read(bytes.NewReader(lineBytes), binary.LittleEndian, &instruction); err != nil {
+		return nil, err
+	}
+	return instruction, nil
+}
+
+// instructionDecoders maps every RE2 opcode in InstructionSize to the decoder of its struct
+var instructionDecoders = map[byte]instructionDecoder{
+	OP_NO_OP:            decodeAs[ScriptInstrNoOp],
+	OP_EVT_END:          decodeAs[ScriptInstrEventEnd],
+	OP_EVT_NEXT:         decodeAs[ScriptInstrEventNext],
+	OP_EVT_CHAIN:        decodeAs[ScriptInstrEventChain],
+	OP_EVT_EXEC:         decodeAs[ScriptInstrEventExec],
+	OP_EVT_KILL:         decodeAs[ScriptInstrEventKill],
+	OP_IF_START:         decodeAs[ScriptInstrIfElseStart],
+	OP_ELSE_START:       decodeAs[ScriptInstrElseStart],
+	OP_END_IF:           decodeAs[ScriptInstrEndIf],
+	OP_SLEEP:            decodeAs[ScriptInstrSleep],
+	OP_SLEEPING:         decodeAs[ScriptInstrSleeping],
+	OP_WSLEEP:           decodeAs[ScriptInstrWsleep],
+	OP_WSLEEPING:        decodeAs[ScriptInstrWsleeping],
+	OP_FOR:              decodeAs[ScriptInstrForStart],
+	OP_FOR_END:          decodeAs[ScriptInstrForEnd],
+	OP_WHILE_START:      decodeAs[ScriptInstrWhileStart],
+	OP_WHILE_END:        decodeAs[ScriptInstrWhileEnd],
+	OP_DO_START:         decodeAs[ScriptInstrDoStart],
+	OP_DO_END:           decodeAs[ScriptInstrDoEnd],
+	OP_SWITCH:           decodeAs[ScriptInstrSwitch],
+	OP_CASE:             decodeAs[ScriptInstrSwitchCase],
+	OP_DEFAULT:          decodeAs[ScriptInstrDefault],
+	OP_END_SWITCH:       decodeAs[ScriptInstrEndSwitch],
+	OP_GOTO:             decodeAs[ScriptInstrGoto],
+	OP_GOSUB:            decodeAs[ScriptInstrGoSub],
+	OP_GOSUB_RETURN:     decodeAs[ScriptInstrGoSubReturn],
+	OP_BREAK:            decodeAs[ScriptInstrBreak],
+	OP_WORK_COPY:        decodeAs[ScriptInstrWorkCopy],
+	OP_NO_OP2:           decodeAs[ScriptInstrNoOp2],
+	OP_CHECK:            decodeAs[ScriptInstrCheckBitTest],
+	OP_SET_BIT:          decodeAs[ScriptInstrSetBit],
+	OP_COMPARE:          decodeAs[ScriptInstrCompare],
+	OP_SAVE:             decodeAs[ScriptInstrSave],
+	OP_COPY:             decodeAs[ScriptInstrCopy],
+	OP_CALC:             decodeAs[ScriptInstrCalc],
+	OP_CALC2:            decodeAs[ScriptInstrCalc2],
+	OP_SCE_RND:          decodeAs[ScriptInstrSceRnd],
+	OP_CUT_CHG:          decodeAs[ScriptInstrCutChg],
+	OP_CUT_OLD:          decodeAs[ScriptInstrCutOld],
+	OP_MESSAGE_ON:       decodeAs[ScriptInstrMessageOn],
+	OP_AOT_SET:          decodeAs[ScriptInstrAotSet],
+	OP_OBJ_MODEL_SET:    decodeAs[ScriptInstrObjModelSet],
+	OP_WORK_SET:         decodeAs[ScriptInstrWorkSet],
+	OP_SPEED_SET:        decodeAs[ScriptInstrSpeedSet],
+	OP_ADD_SPEED:        decodeAs[ScriptInstrAddSpeed],
+	OP_ADD_ASPEED:       decodeAs[ScriptInstrAddAspeed],
+	OP_POS_SET:          decodeAs[ScriptInstrPosSet],
+	OP_DIR_SET:          decodeAs[ScriptInstrDirSet],
+	OP_MEMBER_SET:       decodeAs[ScriptInstrMemberSet],
+	OP_MEMBER_SET2:      decodeAs[ScriptInstrMemberSet2],
+	OP_SE_ON:            decodeAs[ScriptInstrSeOn],
+	OP_SCA_ID_SET:       decodeAs[ScriptInstrScaIdSet],
+	OP_DIR_CK:           decodeAs[ScriptInstrDirCk],
+	OP_SCE_ESPR_ON:      decodeAs[ScriptInstrSceEsprOn],
+	OP_DOOR_AOT_SET:     decodeAs[ScriptInstrDoorAotSet],
+	OP_CUT_AUTO:         decodeAs[ScriptInstrCutAuto],
+	OP_MEMBER_COPY:      decodeAs[ScriptInstrMemberCopy],
+	OP_MEMBER_CMP:       decodeAs[ScriptInstrMemberCompare],
+	OP_PLC_MOTION:       decodeAs[ScriptInstrPlcMotion],
+	OP_PLC_DEST:         decodeAs[ScriptInstrPlcDest],
+	OP_PLC_NECK:         decodeAs[ScriptInstrPlcNeck],
+	OP_PLC_RET:          decodeAs[ScriptInstrPlcRet],
+	OP_PLC_FLAG:         decodeAs[ScriptInstrPlcFlag],
+	OP_SCE_EM_SET:       decodeAs[ScriptInstrSceEmSet],
+	OP_AOT_RESET:        decodeAs[ScriptInstrAotReset],
+	OP_AOT_ON:           decodeAs[ScriptInstrAotOn],
+	OP_SUPER_SET:        decodeAs[ScriptInstrSuperSet],
+	OP_CUT_REPLACE:      decodeAs[ScriptInstrCutReplace],
+	OP_SCE_ESPR_KILL:    decodeAs[ScriptInstrSceEsprKill],
+	OP_DOOR_MODEL_SET:   decodeAs[ScriptInstrDoorModelSet],
+	OP_ITEM_AOT_SET:     decodeAs[ScriptInstrItemAotSet],
+	OP_SCE_TRG_CK:       decodeAs[ScriptInstrSceTrgCk],
+	OP_SCE_BGM_CONTROL:  decodeAs[ScriptInstrSceBgmControl],
+	OP_SCE_ESPR_CONTROL: decodeAs[ScriptInstrSceEsprControl],
+	OP_SCE_FADE_SET:     decodeAs[ScriptInstrSceFadeSet],
+	OP_SCE_ESPR3D_ON:    decodeAs[ScriptInstrSceEspr3DOn],
+	OP_SCE_BGMTBL_SET:   decodeAs[ScriptInstrSceBgmTblSet],
+	OP_PLC_ROT:          decodeAs[ScriptInstrPlcRot],
+	OP_XA_ON:            decodeAs[ScriptInstrXaOn],
+	OP_WEAPON_CHG:       decodeAs[ScriptInstrWeaponChg],
+	OP_PLC_CNT:          decodeAs[ScriptInstrPlcCnt],
+	OP_SCE_SHAKE_ON:     decodeAs[ScriptInstrSceShakeOn],
+	OP_MIZU_DIV_SET:     decodeAs[ScriptInstrMizuDivSet],
+	OP_KEEP_ITEM_CK:     decodeAs[ScriptInstrKeepItemCk],
+	OP_XA_VOL:           decodeAs[ScriptInstrXaVol],
+	OP_KAGE_SET:         decodeAs[ScriptInstrKageSet],
+	OP_CUT_BE_SET:       decodeAs[ScriptInstrCutBeSet],
+	OP_SCE_ITEM_LOST:    decodeAs[ScriptInstrSceItemLost],
+	OP_PLC_GUN_EFF:      decodeAs[ScriptInstrPlcGunEff],
+	OP_SCE_ESPR_ON2:     decodeAs[ScriptInstrSceEsprOn2],
+	OP_SCE_ESPR_KILL2:   decodeAs[ScriptInstrSceEsprKill2],
+	OP_PLC_STOP:         decodeAs[ScriptInstrPlcStop],
+	OP_AOT_SET_4P:       decodeAs[ScriptInstrAotSet4p],
+	OP_DOOR_AOT_SET_4P:  decodeAs[ScriptInstrDoorAotSet4p],
+	OP_ITEM_AOT_SET_4P:  decodeAs[ScriptInstrItemAotSet4p],
+	OP_LIGHT_POS_SET:    decodeAs[ScriptInstrLightPosSet],
+	OP_LIGHT_KIDO_SET:   decodeAs[ScriptInstrLightKidoSet],
+	OP_RBJ_RESET:        decodeAs[ScriptInstrRbjReset],
+	OP_SCE_SCR_MOVE:     decodeAs[ScriptInstrSceScrMove],
+	OP_PARTS_SET:        decodeAs[ScriptInstrPartsSet],
+	OP_MOVIE_ON:         decodeAs[ScriptInstrMovieOn],
+	OP_SCE_PARTS_BOMB:   decodeAs[ScriptInstrScePartsBomb],
+	OP_SCE_PARTS_DOWN:   decodeAs[ScriptInstrScePartsDown],
+}
+
+// InstructionAt returns the decoded instruction starting at the program counter
+func (script ScriptFunction) InstructionAt(programCounter int) (Instruction, bool) {
+	i := sort.Search(len(script.Program), func(i int) bool {
+		return script.Program[i].Offset() >= programCounter
+	})
+	if i < len(script.Program) && script.Program[i].Offset() == programCounter {
+		return script.Program[i], true
+	}
+	return nil, false
+}
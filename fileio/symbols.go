@@ -0,0 +1,359 @@
+package fileio
+
+// Symbolic names for the numbers in script parameters, such as item ids and enemy types
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// SymbolTable maps the numbers used in script parameters to names.
+// The keys are decimal numbers, except for flags ("1:5" is bit 5 of bit array 1)
+// and rooms, which use the room id of the file names (e.g. "100").
+type SymbolTable struct {
+	Items     map[string]string `json:"items" toml:"items"`
+	Enemies   map[string]string `json:"enemies" toml:"enemies"`
+	BitArrays map[string]string `json:"bitArrays" toml:"bitArrays"`
+	Flags     map[string]string `json:"flags" toml:"flags"`
+	Bgm       map[string]string `json:"bgm" toml:"bgm"`
+	Xa        map[string]string `json:"xa" toml:"xa"`
+	Stages    map[string]string `json:"stages" toml:"stages"`
+	Rooms     map[string]string `json:"rooms" toml:"rooms"`
+}
+
+// NewSymbolTable creates an empty symbol table
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		Items:     make(map[string]string),
+		Enemies:   make(map[string]string),
+		BitArrays: make(map[string]string),
+		Flags:     make(map[string]string),
+		Bgm:       make(map[string]string),
+		Xa:        make(map[string]string),
+		Stages:    make(map[string]string),
+		Rooms:     make(map[string]string),
+	}
+}
+
+// Symbols is the symbol table used by the opcode signatures.
+// It starts with the built-in names and can be extended with LoadSymbolFile.
+var Symbols = DefaultSymbols()
+
+// DefaultSymbols returns a new table with the built-in names. Only the items, enemies and bit arrays
+// of RE2 have built-in names. Flags, music, XA tracks, stages and rooms are only named by symbol files,
+// because there is no verified list of their names to ship with the viewer.
+func DefaultSymbols() *SymbolTable {
+	table := NewSymbolTable()
+	for id, name := range defaultItemNames {
+		table.Items[strconv.Itoa(id)] = name
+	}
+	for id, name := range defaultEnemyNames {
+		table.Enemies[strconv.Itoa(id)] = name
+	}
+	for id, name := range defaultBitArrayNames {
+		table.BitArrays[strconv.Itoa(id)] = name
+	}
+	return table
+}
+
+// Merge adds the names of another table, replacing names that are already in the table
+func (table *SymbolTable) Merge(other *SymbolTable) {
+	merge := func(dst map[string]string, src map[string]string) {
+		for key, name := range src {
+			dst[key] = name
+		}
+	}
+	merge(table.Items, other.Items)
+	merge(table.Enemies, other.Enemies)
+	merge(table.BitArrays, other.BitArrays)
+	merge(table.Flags, other.Flags)
+	merge(table.Bgm, other.Bgm)
+	merge(table.Xa, other.Xa)
+	merge(table.Stages, other.Stages)
+	merge(table.Rooms, other.Rooms)
+}
+
+// ReadSymbolTable reads a symbol table in the JSON or TOML format
+func ReadSymbolTable(r io.Reader, format string) (*SymbolTable, error) {
+	table := NewSymbolTable()
+	switch format {
+	case "json":
+		if err := json.NewDecoder(r).Decode(table); err != nil {
+			return nil, err
+		}
+	case "toml":
+		if _, err := toml.NewDecoder(r).Decode(table); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown symbol file format %q", format)
+	}
+	return table, nil
+}
+
+// LoadSymbolFile adds the names of a .json or .toml file to Symbols
+func LoadSymbolFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	table, err := ReadSymbolTable(file, strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), "."))
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	Symbols.Merge(table)
+	return nil
+}
+
+// WriteTOML writes the table in the TOML format that LoadSymbolFile reads
+func (table *SymbolTable) WriteTOML(w io.Writer) error {
+	return toml.NewEncoder(w).Encode(table)
+}
+
+// WriteJSON writes the table in the JSON format that LoadSymbolFile reads
+func (table *SymbolTable) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(table)
+}
+
+// Item returns the name of an item, or an empty string if it has no name
+func (table *SymbolTable) Item(id int) string {
+	return table.Items[strconv.Itoa(id)]
+}
+
+// Enemy returns the name of an enemy type
+func (table *SymbolTable) Enemy(id int) string {
+	return table.Enemies[strconv.Itoa(id)]
+}
+
+// BitArray returns the name of a bit array
+func (table *SymbolTable) BitArray(bitArray int) string {
+	return table.BitArrays[strconv.Itoa(bitArray)]
+}
+
+// Flag returns the name of a single bit in a bit array
+func (table *SymbolTable) Flag(bitArray int, bitNumber int) string {
+	return table.Flags[fmt.Sprintf("%d:%d", bitArray, bitNumber)]
+}
+
+// BgmName returns the name of a background music track
+func (table *SymbolTable) BgmName(id int) string {
+	return table.Bgm[strconv.Itoa(id)]
+}
+
+// XaName returns the name of an XA audio track
+func (table *SymbolTable) XaName(id int) string {
+	return table.Xa[strconv.Itoa(id)]
+}
+
+// Stage returns the name of a stage. The stage number starts at 0 as in the scripts.
+func (table *SymbolTable) Stage(stage int) string {
+	return table.Stages[strconv.Itoa(stage)]
+}
+
+// Room returns the name of a room. The stage number starts at 0 as in the scripts.
+func (table *SymbolTable) Room(stage uint8, room uint8) string {
+	return table.Rooms[RoomId(stage, room)]
+}
+
+// SortedKeys returns the keys of a name map in numerical order where possible
+func SortedKeys(names map[string]string) []string {
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// formatSymbolComment returns the name as a comment after a parameter, or an empty string if it has no name.
+// The assembler skips these comments, so the signatures can still be assembled.
+func formatSymbolComment(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " /* " + strings.ReplaceAll(name, "*/", "* /") + " */"
+}
+
+// Built-in item names, using the names shown in the inventory of the English release
+var defaultItemNames = map[int]string{
+	0x01: "Knife",
+	0x02: "H&K VP70",
+	0x03: "Browning HP",
+	0x04: "Custom Handgun",
+	0x05: "Magnum",
+	0x06: "Custom Magnum",
+	0x07: "Shotgun",
+	0x08: "Custom Shotgun",
+	0x09: "Grenade Launcher (Explosive)",
+	0x0a: "Grenade Launcher (Flame)",
+	0x0b: "Grenade Launcher (Acid)",
+	0x0c: "Bowgun",
+	0x0d: "Colt S.A.A.",
+	0x0e: "Spark Shot",
+	0x0f: "Sub Machine Gun",
+	0x10: "Flamethrower",
+	0x11: "Rocket Launcher",
+	0x12: "Gatling Gun",
+	0x13: "Beretta",
+	0x14: "Handgun Bullets",
+	0x15: "Shotgun Shells",
+	0x16: "Magnum Rounds",
+	0x17: "Fuel",
+	0x18: "Grenade Rounds",
+	0x19: "Flame Rounds",
+	0x1a: "Acid Rounds",
+	0x1b: "Machine Gun Bullets",
+	0x1c: "Spark Shot Bullets",
+	0x1d: "Bowgun Bolts",
+	0x1e: "Ink Ribbon",
+	0x1f: "Small Key",
+	0x20: "Handgun Parts",
+	0x21: "Magnum Parts",
+	0x22: "Shotgun Parts",
+	0x23: "First Aid Spray",
+	0x24: "Chemical FR-W09",
+	0x25: "Chemical ACw-32",
+	0x26: "Green Herb",
+	0x27: "Red Herb",
+	0x28: "Blue Herb",
+	0x29: "Mixed Herb (G+G)",
+	0x2a: "Mixed Herb (R+G)",
+	0x2b: "Mixed Herb (B+G)",
+	0x2c: "Mixed Herb (G+G+G)",
+	0x2d: "Mixed Herb (G+G+B)",
+	0x2e: "Mixed Herb (R+G+B)",
+	0x2f: "Lighter",
+	0x30: "Lock Pick",
+	0x31: "Photo (Sherry)",
+	0x32: "Valve Handle",
+	0x33: "Red Jewel",
+	0x34: "Red Card Key",
+	0x35: "Blue Card Key",
+	0x36: "Serpent Stone",
+	0x37: "Jaguar Stone",
+	0x38: "Blue Stone (Left)",
+	0x39: "Blue Stone (Right)",
+	0x3a: "Eagle Stone",
+	0x3b: "Bishop Plug",
+	0x3c: "Rook Plug",
+	0x3d: "Knight Plug",
+	0x3e: "King Plug",
+	0x3f: "Weapon Box Key",
+	0x40: "Detonator",
+	0x41: "Plastic Explosive",
+	0x42: "Bomb & Detonator",
+	0x43: "Crank",
+	0x44: "Film A",
+	0x45: "Film B",
+	0x46: "Film C",
+	0x47: "Unicorn Medal",
+	0x48: "Eagle Medal",
+	0x49: "Wolf Medal",
+	0x4a: "G. Cogwheel",
+	0x4b: "Manhole Opener",
+	0x4c: "Main Fuse",
+	0x4d: "Fuse Case",
+	0x4e: "Vaccine",
+	0x4f: "Vaccine Cart",
+	0x50: "Film D",
+	0x51: "Vaccine Base",
+	0x52: "G-Virus",
+	0x53: "Special Key",
+	0x54: "Joint S Plug",
+	0x55: "Joint N Plug",
+	0x56: "Cord",
+	0x57: "Film",
+	0x58: "Cabin Key",
+	0x59: "Precinct Key (Blue)",
+	0x5a: "Precinct Key (Red)",
+	0x5b: "Precinct Key (Grey)",
+	0x5c: "Precinct Key (Green)",
+	0x5d: "Spade Key",
+	0x5e: "Diamond Key",
+	0x5f: "Heart Key",
+	0x60: "Club Key",
+	0x61: "Control Panel Key (Down)",
+	0x62: "Control Panel Key (Up)",
+	0x63: "Power Room Key",
+	0x64: "MO Disk",
+	0x65: "Umbrella Key Card",
+	0x66: "Master Key",
+	0x67: "Platform Key",
+}
+
+// Built-in enemy and character names for the Type parameter of SceEmSet
+var defaultEnemyNames = map[int]string{
+	0x10: "Zombie (Cop)",
+	0x11: "Zombie (Brad)",
+	0x12: "Zombie (Male)",
+	0x13: "Zombie (Female)",
+	0x15: "Zombie (Lab)",
+	0x16: "Zombie (Scientist)",
+	0x17: "Zombie (Naked)",
+	0x18: "Zombie (Male 2)",
+	0x1e: "Zombie (Male 3)",
+	0x1f: "Zombie (Male 4)",
+	0x20: "Cerberus",
+	0x21: "Crow",
+	0x22: "Licker",
+	0x23: "Alligator",
+	0x24: "Licker (Grey)",
+	0x25: "Spider",
+	0x26: "Baby Spider",
+	0x27: "G Embryo",
+	0x28: "G Adult",
+	0x29: "Cockroach",
+	0x2a: "Mr. X",
+	0x2b: "Super Mr. X",
+	0x30: "G (1st form)",
+	0x31: "G (2nd form)",
+	0x32: "G (3rd form)",
+	0x33: "G (4th form)",
+	0x34: "G (5th form)",
+	0x36: "Ivy",
+	0x37: "Moth",
+	0x38: "Larva",
+	0x40: "Chief Irons",
+	0x41: "Ada",
+	0x42: "Chief Irons (2)",
+	0x43: "Ada (2)",
+	0x44: "Ben",
+	0x45: "Sherry",
+	0x46: "Ben (2)",
+	0x47: "Annette",
+}
+
+// Built-in names of the bit arrays that CheckBit and SetBit refer to
+var defaultBitArrayNames = map[int]string{
+	0:  "system",
+	1:  "status",
+	2:  "stop",
+	3:  "scenario",
+	4:  "common",
+	5:  "room",
+	6:  "enemy",
+	7:  "enemy2",
+	8:  "item",
+	9:  "map",
+	10: "use",
+	11: "message",
+}
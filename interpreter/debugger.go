@@ -0,0 +1,81 @@
+package interpreter
+
+// Stepping through the threads with breakpoints
+
+import (
+	"fmt"
+)
+
+// MaxDebugSteps stops Continue, StepOver and RunTo when no breakpoint is reached
+const MaxDebugSteps = 1000000
+
+// Location is the position of an instruction in the init script or the room script
+type Location struct {
+	Init           bool
+	ProgramCounter int
+}
+
+// Location returns the position of the next instruction of the thread
+func (thread *Thread) Location() Location {
+	return Location{Init: thread.Init, ProgramCounter: thread.ProgramCounter}
+}
+
+// ToggleBreakpoint adds or removes a breakpoint and returns true if it was added
+func (interp *Interpreter) ToggleBreakpoint(location Location) bool {
+	if interp.Breakpoints[location] {
+		delete(interp.Breakpoints, location)
+		return false
+	}
+	interp.Breakpoints[location] = true
+	return true
+}
+
+// atBreakpoint returns true if the next instruction has a breakpoint
+func (interp *Interpreter) atBreakpoint() bool {
+	thread, _, ok := interp.Current()
+	return ok && interp.Breakpoints[thread.Location()]
+}
+
+// Continue runs until the next instruction has a breakpoint or every thread has finished
+func (interp *Interpreter) Continue() (bool, error) {
+	return interp.runUntil(func() bool { return interp.atBreakpoint() })
+}
+
+// StepOver runs a single instruction of the current thread. A Gosub runs until the called function returns.
+func (interp *Interpreter) StepOver() (bool, error) {
+	thread, _, ok := interp.Current()
+	if !ok {
+		return interp.Step()
+	}
+	depth := thread.CallDepth()
+	running, err := interp.Step()
+	if !running || err != nil || thread.Finished || thread.CallDepth() <= depth {
+		return running, err
+	}
+	return interp.runUntil(func() bool {
+		current, _, ok := interp.Current()
+		return interp.atBreakpoint() || thread.Finished || (ok && current == thread && thread.CallDepth() <= depth)
+	})
+}
+
+// RunTo runs until any thread reaches the location, e.g. the line under the cursor
+func (interp *Interpreter) RunTo(location Location) (bool, error) {
+	return interp.runUntil(func() bool {
+		thread, _, ok := interp.Current()
+		return interp.atBreakpoint() || (ok && thread.Location() == location)
+	})
+}
+
+// runUntil runs at least one instruction and stops before the next instruction when done returns true
+func (interp *Interpreter) runUntil(done func() bool) (bool, error) {
+	for steps := 0; steps < MaxDebugSteps; steps++ {
+		running, err := interp.Step()
+		if !running || err != nil {
+			return running, err
+		}
+		if done() {
+			return true, nil
+		}
+	}
+	return true, fmt.Errorf("stopped after %d instructions", MaxDebugSteps)
+}
@@ -0,0 +1,578 @@
+// Package interpreter simulates the script engine of the game on the parsed bytecode.
+//
+// Every event runs on its own ScriptThread. In each tick of the game loop, the
+// threads run one after another until they wait with Sleep, Wsleep or EvtNext
+// or until their function ends. The interpreter keeps the flag bit arrays, the
+// variables and the members of the work objects in a State, and follows the
+// control flow of if/else, loop, switch, Goto and Gosub blocks. Instructions that
+// need the rest of the engine, such as cameras, sounds and models, are not
+// executed but recorded as events.
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+const (
+	// InitThreadId is the id of the thread that runs the init script of a room
+	InitThreadId = -1
+
+	// MaxInstructionsPerTick stops threads that loop without waiting for the next tick
+	MaxInstructionsPerTick = 10000
+)
+
+type blockKind int
+
+const (
+	blockFor blockKind = iota
+	blockWhile
+	blockDo
+	blockSwitch
+)
+
+// block is a loop or switch that the thread is inside of
+type block struct {
+	kind  blockKind
+	start int // program counter where the next iteration starts
+	end   int // program counter after the block
+	count int // iterations left of a for loop
+}
+
+// frame is the state of a function that called Gosub
+type frame struct {
+	returnProgramCounter int
+	blocks               []block
+}
+
+// Thread is a ScriptThread that runs one event
+type Thread struct {
+	Id             int
+	Init           bool // runs the init script instead of the room script
+	Function       int  // function that started the thread
+	ProgramCounter int
+	Sleep          int  // ticks left to wait
+	Work           Work // object selected with WorkSet
+	Finished       bool
+
+	script     *fileio.ScriptFunction
+	calls      []frame
+	blocks     []block
+	conditions bool // all conditions since the start of the current do loop were true
+	yielded    bool // waits for the next tick
+}
+
+// CallDepth returns the number of Gosub calls that have not returned yet
+func (thread *Thread) CallDepth() int {
+	return len(thread.calls)
+}
+
+// ScriptFilename returns the script file of the function that the thread is running,
+// using the file names of fileio.SplitRDTScripts
+func (thread *Thread) ScriptFilename() string {
+	if thread.Init {
+		return "init.scd"
+	}
+	return fmt.Sprintf("sub%d.scd", functionAt(thread.script, thread.ProgramCounter))
+}
+
+// Event is an instruction that needs the rest of the game engine, e.g. to move the camera,
+// play a sound or show a model. These instructions are recorded instead of executed.
+type Event struct {
+	Tick        int
+	ThreadId    int
+	Instruction fileio.Instruction
+}
+
+func (event Event) String() string {
+	return fmt.Sprintf("tick %d thread %d %04x: %s%s", event.Tick, event.ThreadId,
+		event.Instruction.Offset(), event.Instruction.Name(), fileio.GetOpcodeSignature(event.Instruction))
+}
+
+// Interpreter runs the threads of a room script
+type Interpreter struct {
+	State   *State
+	Threads []*Thread
+	Events  []Event
+	Tick    int
+
+	// EngineCondition decides the conditions that depend on the engine, such as
+	// SceTrgCk and DirCk. These conditions are false if it is not set.
+	EngineCondition func(thread *Thread, instruction fileio.Instruction) bool
+
+	// Breakpoints stop Continue, StepOver and RunTo before the instruction runs
+	Breakpoints map[Location]bool
+
+	initScript         fileio.ScriptFunction
+	roomScript         fileio.ScriptFunction
+	current            int // index of the thread that runs next in this tick
+	executed           int // instructions run by the current thread in this tick
+	nextThreadId       int
+	pendingRoomThreads bool // sub0 and sub1 start when the init thread has finished
+}
+
+// New creates an interpreter for the scripts of a room without starting any threads.
+// The scripts change the state, which is a new empty state if it is nil.
+func New(rdtOutput *fileio.RDTOutput, state *State) *Interpreter {
+	if state == nil {
+		state = NewState()
+	}
+	interp := &Interpreter{State: state, Breakpoints: make(map[Location]bool)}
+	if rdtOutput.InitScriptData != nil {
+		interp.initScript = rdtOutput.InitScriptData.ScriptData
+	}
+	if rdtOutput.RoomScriptData != nil {
+		interp.roomScript = rdtOutput.RoomScriptData.ScriptData
+	}
+	return interp
+}
+
+// NewRoom creates an interpreter that starts like the game when the player enters the room.
+// The init script runs first on its own thread, which stops at breakpoints and can be stepped
+// through like any other thread. When it has finished, sub0 and sub1 are started on
+// ScriptThread0 and ScriptThread1.
+func NewRoom(rdtOutput *fileio.RDTOutput, state *State) (*Interpreter, error) {
+	interp := New(rdtOutput, state)
+	if len(interp.initScript.StartProgramCounter) > 0 {
+		interp.startThread(&interp.initScript, InitThreadId, 0)
+	}
+	interp.pendingRoomThreads = true
+	interp.startRoomThreads()
+	return interp, nil
+}
+
+// startRoomThreads starts sub0 and sub1 once the init thread of NewRoom has finished
+func (interp *Interpreter) startRoomThreads() {
+	if !interp.pendingRoomThreads {
+		return
+	}
+	for _, thread := range interp.Threads {
+		if thread.Init && !thread.Finished {
+			return
+		}
+	}
+	interp.pendingRoomThreads = false
+	for function := 0; function < 2 && function < len(interp.roomScript.StartProgramCounter); function++ {
+		interp.StartThread(function)
+	}
+}
+
+// StartThread starts a new thread that runs a function of the room script
+func (interp *Interpreter) StartThread(function int) (*Thread, error) {
+	if function < 0 || function >= len(interp.roomScript.StartProgramCounter) {
+		return nil, fmt.Errorf("room script has no function %d", function)
+	}
+	thread := interp.startThread(&interp.roomScript, interp.nextThreadId, function)
+	interp.nextThreadId++
+	return thread, nil
+}
+
+func (interp *Interpreter) startThread(script *fileio.ScriptFunction, id int, function int) *Thread {
+	thread := &Thread{
+		Id:             id,
+		Init:           script == &interp.initScript,
+		Function:       function,
+		ProgramCounter: script.StartProgramCounter[function],
+		script:         script,
+		conditions:     true,
+	}
+	interp.Threads = append(interp.Threads, thread)
+	return thread
+}
+
+// Running returns true while any thread has not finished or the room threads have not started yet
+func (interp *Interpreter) Running() bool {
+	if interp.pendingRoomThreads {
+		return true
+	}
+	for _, thread := range interp.Threads {
+		if !thread.Finished {
+			return true
+		}
+	}
+	return false
+}
+
+// Current returns the thread that runs the next instruction and the instruction itself.
+// When every thread of the tick has run, the next tick starts first.
+func (interp *Interpreter) Current() (*Thread, fileio.Instruction, bool) {
+	if !interp.advance() {
+		return nil, nil, false
+	}
+	thread := interp.Threads[interp.current]
+	instruction, _ := thread.script.InstructionAt(thread.ProgramCounter)
+	return thread, instruction, true
+}
+
+func (thread *Thread) runnable() bool {
+	return !thread.Finished && !thread.yielded && thread.Sleep == 0
+}
+
+// advance moves to the next thread that can run and starts new ticks until one can.
+// It returns false when every thread has finished.
+func (interp *Interpreter) advance() bool {
+	interp.startRoomThreads()
+	for interp.Running() {
+		for interp.current < len(interp.Threads) && !interp.Threads[interp.current].runnable() {
+			interp.current++
+			interp.executed = 0
+		}
+		if interp.current < len(interp.Threads) {
+			return true
+		}
+		interp.nextTick()
+	}
+	return false
+}
+
+// Step runs a single instruction of the current thread.
+// It returns false when every thread has finished.
+func (interp *Interpreter) Step() (bool, error) {
+	if !interp.advance() {
+		return false, nil
+	}
+
+	thread := interp.Threads[interp.current]
+	err := interp.execute(thread)
+	interp.executed++
+	if interp.executed >= MaxInstructionsPerTick {
+		thread.yielded = true
+	}
+	if err != nil {
+		thread.Finished = true
+		return true, fmt.Errorf("thread %d: %w", thread.Id, err)
+	}
+	return true, nil
+}
+
+// RunTick runs instructions until every thread has waited for the next tick or finished
+func (interp *Interpreter) RunTick() error {
+	tick := interp.Tick
+	for interp.Tick == tick {
+		running, err := interp.Step()
+		if err != nil {
+			return err
+		}
+		if !running {
+			return nil
+		}
+		// Starts the next tick after the last thread of this tick
+		interp.advance()
+	}
+	return nil
+}
+
+// nextTick removes the finished threads and wakes up the sleeping ones
+func (interp *Interpreter) nextTick() {
+	threads := interp.Threads[:0]
+	for _, thread := range interp.Threads {
+		if thread.Finished {
+			continue
+		}
+		thread.yielded = false
+		if thread.Sleep > 0 {
+			thread.Sleep--
+		}
+		threads = append(threads, thread)
+	}
+	interp.Threads = threads
+	interp.current = 0
+	interp.executed = 0
+	interp.Tick++
+}
+
+// record adds an instruction that is handled by the rest of the engine to the events
+func (interp *Interpreter) record(thread *Thread, instruction fileio.Instruction) {
+	interp.Events = append(interp.Events, Event{Tick: interp.Tick, ThreadId: thread.Id, Instruction: instruction})
+}
+
+// execute runs the instruction at the program counter of the thread
+func (interp *Interpreter) execute(thread *Thread) error {
+	instruction, exists := thread.script.InstructionAt(thread.ProgramCounter)
+	if !exists {
+		return fmt.Errorf("no instruction at program counter %d", thread.ProgramCounter)
+	}
+	next := instruction.Offset() + instruction.Length()
+	thread.ProgramCounter = next
+
+	switch decoded := instruction.Decoded().(type) {
+	case fileio.ScriptInstrEventEnd, fileio.ScriptInstrGoSubReturn:
+		interp.returnFromFunction(thread)
+	case fileio.ScriptInstrEventNext, fileio.ScriptInstrWsleep, fileio.ScriptInstrWsleeping:
+		thread.yielded = true
+	case fileio.ScriptInstrEventExec:
+		if _, err := interp.StartThread(int(decoded.Event)); err != nil {
+			return err
+		}
+	case fileio.ScriptInstrEventKill:
+		for _, other := range interp.Threads {
+			if !other.Init && other.Function == int(decoded.Event) {
+				other.Finished = true
+			}
+		}
+	case fileio.ScriptInstrSleep:
+		thread.Sleep = int(decoded.Count)
+		thread.yielded = true
+	case fileio.ScriptInstrSleeping:
+		thread.Sleep = int(decoded.Count)
+		thread.yielded = true
+
+	case fileio.ScriptInstrIfElseStart:
+		end := next + int(decoded.BlockLength)
+		bodyStart, ok := interp.checkConditions(thread, next, end)
+		if ok {
+			thread.ProgramCounter = bodyStart
+		} else {
+			thread.ProgramCounter = end
+		}
+	case fileio.ScriptInstrElseStart:
+		// The if block was executed, so the else block is skipped
+		thread.ProgramCounter = instruction.Offset() + int(decoded.BlockLength)
+	case fileio.ScriptInstrForStart:
+		end := next + int(decoded.BlockLength)
+		if decoded.Count == 0 {
+			thread.ProgramCounter = skipInstruction(thread.script, end, fileio.OP_FOR_END)
+		} else {
+			thread.blocks = append(thread.blocks, block{kind: blockFor, start: next, end: end, count: int(decoded.Count)})
+		}
+	case fileio.ScriptInstrForEnd:
+		if loop := thread.innermostBlock(blockFor); loop != nil {
+			loop.count--
+			if loop.count > 0 {
+				thread.ProgramCounter = loop.start
+			} else {
+				thread.popBlock()
+			}
+		}
+	case fileio.ScriptInstrWhileStart:
+		end := next + int(decoded.BlockLength)
+		bodyStart, ok := interp.checkConditions(thread, next, end)
+		if ok {
+			thread.blocks = append(thread.blocks, block{kind: blockWhile, start: instruction.Offset(), end: end})
+			thread.ProgramCounter = bodyStart
+		} else {
+			thread.ProgramCounter = skipInstruction(thread.script, end, fileio.OP_WHILE_END)
+		}
+	case fileio.ScriptInstrWhileEnd:
+		if loop := thread.innermostBlock(blockWhile); loop != nil {
+			// The conditions are checked again by the while instruction
+			thread.ProgramCounter = loop.start
+			thread.popBlock()
+		}
+	case fileio.ScriptInstrDoStart:
+		thread.blocks = append(thread.blocks, block{kind: blockDo, start: next, end: next + int(decoded.BlockLength)})
+		thread.conditions = true
+	case fileio.ScriptInstrDoEnd:
+		// The conditions are the instructions before DoEnd
+		if loop := thread.innermostBlock(blockDo); loop != nil {
+			if thread.conditions {
+				thread.ProgramCounter = loop.start
+			} else {
+				thread.popBlock()
+			}
+			thread.conditions = true
+		}
+	case fileio.ScriptInstrSwitch:
+		end := next + int(decoded.BlockLength)
+		thread.blocks = append(thread.blocks, block{kind: blockSwitch, end: end})
+		caseStart, ok := findCase(thread.script, next, end, uint16(interp.State.Variables[decoded.VarId]))
+		if ok {
+			thread.ProgramCounter = caseStart
+		} else {
+			thread.exitBlock()
+		}
+	case fileio.ScriptInstrEndSwitch:
+		if len(thread.blocks) > 0 && thread.blocks[len(thread.blocks)-1].kind == blockSwitch {
+			thread.popBlock()
+		}
+	case fileio.ScriptInstrBreak:
+		// Break leaves the innermost switch or loop
+		if len(thread.blocks) > 0 {
+			thread.exitBlock()
+		}
+	case fileio.ScriptInstrGoto:
+		if decoded.LoopLevel >= 0 && int(decoded.LoopLevel) < len(thread.blocks) {
+			thread.blocks = thread.blocks[:decoded.LoopLevel]
+		}
+		thread.ProgramCounter = instruction.Offset() + int(decoded.Offset)
+	case fileio.ScriptInstrGoSub:
+		if int(decoded.Event) >= len(thread.script.StartProgramCounter) {
+			return fmt.Errorf("gosub to missing function %d", decoded.Event)
+		}
+		thread.calls = append(thread.calls, frame{returnProgramCounter: next, blocks: thread.blocks})
+		thread.blocks = nil
+		thread.ProgramCounter = thread.script.StartProgramCounter[decoded.Event]
+
+	case fileio.ScriptInstrCheckBitTest, fileio.ScriptInstrCompare, fileio.ScriptInstrMemberCompare,
+		fileio.ScriptInstrKeepItemCk, fileio.ScriptInstrSceTrgCk, fileio.ScriptInstrDirCk:
+		// Conditions outside of if and while blocks are the conditions of a do loop
+		if !interp.checkCondition(thread, instruction) {
+			thread.conditions = false
+		}
+	case fileio.ScriptInstrSetBit:
+		switch decoded.Operation {
+		case fileio.SET_BIT_CLEAR:
+			interp.State.SetBit(decoded.BitArray, decoded.BitNumber, false)
+		case fileio.SET_BIT_SET:
+			interp.State.SetBit(decoded.BitArray, decoded.BitNumber, true)
+		case fileio.SET_BIT_FLIP:
+			interp.State.SetBit(decoded.BitArray, decoded.BitNumber, !interp.State.Bit(decoded.BitArray, decoded.BitNumber))
+		}
+	case fileio.ScriptInstrSave:
+		interp.State.Variables[decoded.VarId] = decoded.Value
+	case fileio.ScriptInstrCopy:
+		interp.State.Variables[decoded.DestVarId] = interp.State.Variables[decoded.SourceVarId]
+	case fileio.ScriptInstrCalc:
+		variables := &interp.State.Variables
+		variables[decoded.VarId] = calculate(decoded.Operation, variables[decoded.VarId], decoded.Value)
+	case fileio.ScriptInstrCalc2:
+		variables := &interp.State.Variables
+		variables[decoded.VarId] = calculate(decoded.Operation, variables[decoded.VarId], variables[decoded.SourceVarId])
+	case fileio.ScriptInstrWorkSet:
+		thread.Work = Work{Component: decoded.Component, Index: decoded.Index}
+	case fileio.ScriptInstrMemberSet:
+		interp.State.SetMember(thread.Work, decoded.MemberIndex, int16(decoded.Value))
+	case fileio.ScriptInstrMemberSet2:
+		interp.State.SetMember(thread.Work, decoded.MemberIndex, interp.State.Variables[decoded.VarId])
+	case fileio.ScriptInstrMemberCopy:
+		interp.State.Variables[decoded.VarId] = interp.State.Member(thread.Work, decoded.MemberIndex)
+
+	case fileio.ScriptInstrNoOp, fileio.ScriptInstrNoOp2, fileio.ScriptInstrEndIf,
+		fileio.ScriptInstrSwitchCase, fileio.ScriptInstrDefault:
+		// Case labels that are reached from the previous case fall through
+	default:
+		interp.record(thread, instruction)
+	}
+	return nil
+}
+
+// returnFromFunction continues after the last Gosub or finishes the thread
+func (interp *Interpreter) returnFromFunction(thread *Thread) {
+	if len(thread.calls) == 0 {
+		thread.Finished = true
+		return
+	}
+	caller := thread.calls[len(thread.calls)-1]
+	thread.calls = thread.calls[:len(thread.calls)-1]
+	thread.ProgramCounter = caller.returnProgramCounter
+	thread.blocks = caller.blocks
+}
+
+// checkConditions evaluates the condition instructions at the start of an if or while block.
+// It returns the program counter after the conditions and whether all of them are true.
+func (interp *Interpreter) checkConditions(thread *Thread, programCounter int, end int) (int, bool) {
+	for programCounter < end {
+		instruction, exists := thread.script.InstructionAt(programCounter)
+		if !exists || !isCondition(instruction) {
+			break
+		}
+		if !interp.checkCondition(thread, instruction) {
+			return programCounter, false
+		}
+		programCounter += instruction.Length()
+	}
+	return programCounter, true
+}
+
+func isCondition(instruction fileio.Instruction) bool {
+	switch instruction.Decoded().(type) {
+	case fileio.ScriptInstrCheckBitTest, fileio.ScriptInstrCompare, fileio.ScriptInstrMemberCompare,
+		fileio.ScriptInstrKeepItemCk, fileio.ScriptInstrSceTrgCk, fileio.ScriptInstrDirCk:
+		return true
+	}
+	return false
+}
+
+// checkCondition evaluates a single condition instruction
+func (interp *Interpreter) checkCondition(thread *Thread, instruction fileio.Instruction) bool {
+	state := interp.State
+	switch decoded := instruction.Decoded().(type) {
+	case fileio.ScriptInstrCheckBitTest:
+		return state.Bit(decoded.BitArray, decoded.BitNumber) == (decoded.Value != 0)
+	case fileio.ScriptInstrCompare:
+		return compare(decoded.Operation, state.Variables[decoded.VarId], decoded.Value)
+	case fileio.ScriptInstrMemberCompare:
+		return compare(decoded.CompareOperation, state.Member(thread.Work, decoded.MemberIndex), decoded.Value)
+	case fileio.ScriptInstrKeepItemCk:
+		return state.Items[decoded.ItemId]
+	}
+
+	// Triggers and directions depend on the position of the player
+	interp.record(thread, instruction)
+	if interp.EngineCondition == nil {
+		return false
+	}
+	return interp.EngineCondition(thread, instruction)
+}
+
+func (thread *Thread) popBlock() {
+	thread.blocks = thread.blocks[:len(thread.blocks)-1]
+}
+
+// innermostBlock returns the innermost block if it has the kind
+func (thread *Thread) innermostBlock(kind blockKind) *block {
+	if len(thread.blocks) == 0 || thread.blocks[len(thread.blocks)-1].kind != kind {
+		return nil
+	}
+	return &thread.blocks[len(thread.blocks)-1]
+}
+
+// closingOpcodes are the instructions that close each kind of block
+var closingOpcodes = map[blockKind]byte{
+	blockFor:    fileio.OP_FOR_END,
+	blockWhile:  fileio.OP_WHILE_END,
+	blockDo:     fileio.OP_DO_END,
+	blockSwitch: fileio.OP_END_SWITCH,
+}
+
+// exitBlock leaves the innermost block, including its closing instruction
+func (thread *Thread) exitBlock() {
+	inner := thread.blocks[len(thread.blocks)-1]
+	thread.popBlock()
+	thread.ProgramCounter = skipInstruction(thread.script, inner.end, closingOpcodes[inner.kind])
+}
+
+// skipInstruction skips the instruction closing a block if it directly follows the block
+func skipInstruction(script *fileio.ScriptFunction, programCounter int, opcode byte) int {
+	if instruction, exists := script.InstructionAt(programCounter); exists && instruction.Opcode() == opcode {
+		return programCounter + instruction.Length()
+	}
+	return programCounter
+}
+
+// findCase returns the program counter after the case label matching the value,
+// or after the default label if there is no matching case
+func findCase(script *fileio.ScriptFunction, programCounter int, end int, value uint16) (int, bool) {
+	defaultStart, hasDefault := 0, false
+	depth := 0
+	for programCounter < end {
+		instruction, exists := script.InstructionAt(programCounter)
+		if !exists {
+			break
+		}
+		programCounter += instruction.Length()
+
+		switch decoded := instruction.Decoded().(type) {
+		case fileio.ScriptInstrSwitch:
+			depth++
+		case fileio.ScriptInstrEndSwitch:
+			depth--
+		case fileio.ScriptInstrSwitchCase:
+			if depth == 0 && decoded.Value == value {
+				return programCounter, true
+			}
+		case fileio.ScriptInstrDefault:
+			if depth == 0 && !hasDefault {
+				defaultStart, hasDefault = programCounter, true
+			}
+		}
+	}
+	return defaultStart, hasDefault
+}
+
+// functionAt returns the index of the function that contains the program counter
+func functionAt(script *fileio.ScriptFunction, programCounter int) int {
+	return max(sort.SearchInts(script.StartProgramCounter, programCounter+1)-1, 0)
+}
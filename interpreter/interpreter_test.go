@@ -0,0 +1,194 @@
+package interpreter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// Bytecode of the instructions used by the test scripts
+
+func save(varId byte, value byte) []byte {
+	return []byte{fileio.OP_SAVE, varId, value, 0}
+}
+
+func calc(operation byte, varId byte, value byte) []byte {
+	return []byte{fileio.OP_CALC, 0, operation, varId, value, 0}
+}
+
+func setBit(bitArray byte, bitNumber byte, operation byte) []byte {
+	return []byte{fileio.OP_SET_BIT, bitArray, bitNumber, operation}
+}
+
+func checkBit(bitArray byte, bitNumber byte, value byte) []byte {
+	return []byte{fileio.OP_CHECK, bitArray, bitNumber, value}
+}
+
+func compareVar(varId byte, operation byte, value byte) []byte {
+	return []byte{fileio.OP_COMPARE, 0, varId, operation, value, 0}
+}
+
+func sleep(count byte) []byte {
+	return []byte{fileio.OP_SLEEP, fileio.OP_SLEEPING, count, 0}
+}
+
+// ifElse builds an if block with an else branch. IfStart counts its length from its end,
+// ElseStart from its own offset.
+func ifElse(conditions []byte, body []byte, elseBody []byte) []byte {
+	elseStart := []byte{fileio.OP_ELSE_START, 0, byte(4 + len(elseBody)), 0}
+	block := bytes.Join([][]byte{conditions, body, elseStart}, nil)
+	return bytes.Join([][]byte{{fileio.OP_IF_START, 0, byte(len(block)), 0}, block, elseBody, {fileio.OP_END_IF}}, nil)
+}
+
+var (
+	evtEnd      = []byte{fileio.OP_EVT_END}
+	evtNext     = []byte{fileio.OP_EVT_NEXT}
+	gosubReturn = []byte{fileio.OP_GOSUB_RETURN, 0}
+	cutChg      = []byte{fileio.OP_CUT_CHG, 3}
+)
+
+// newTestInterpreter parses the functions as the room script of an RE2 room
+func newTestInterpreter(t *testing.T, functions ...[][]byte) *Interpreter {
+	t.Helper()
+	opcodes, err := fileio.GameRE2.Opcodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	functionBytes := make([][]byte, len(functions))
+	for i, function := range functions {
+		functionBytes[i] = bytes.Join(function, nil)
+	}
+	script := fileio.BuildSCD(functionBytes)
+	output, err := fileio.LoadRDT_SCDStream(bytes.NewReader(script), int64(len(script)), opcodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Diagnostics) != 0 {
+		t.Fatalf("bytecode has problems: %v", output.Diagnostics)
+	}
+	return New(&fileio.RDTOutput{RoomScriptData: output, Opcodes: opcodes}, nil)
+}
+
+// runToEnd runs the threads until every thread has finished
+func runToEnd(t *testing.T, interp *Interpreter) {
+	t.Helper()
+	for i := 0; i < 1000; i++ {
+		running, err := interp.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !running {
+			return
+		}
+	}
+	t.Fatal("threads are still running after 1000 instructions")
+}
+
+func TestThreadsTakeTurnsEachTick(t *testing.T) {
+	interp := newTestInterpreter(t,
+		[][]byte{save(0, 1), evtNext, save(0, 3), evtEnd},
+		[][]byte{calc(CALC_MUL, 0, 10), evtNext, calc(CALC_ADD, 0, 2), evtEnd},
+	)
+	for function := 0; function < 2; function++ {
+		if _, err := interp.StartThread(function); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := interp.RunTick(); err != nil {
+		t.Fatal(err)
+	}
+	if interp.Tick != 1 || interp.State.Variables[0] != 10 {
+		t.Errorf("after the first tick: tick %d, var[0] = %d, want tick 1 and 10", interp.Tick, interp.State.Variables[0])
+	}
+	runToEnd(t, interp)
+	if interp.Tick != 1 || interp.State.Variables[0] != 5 {
+		t.Errorf("at the end: tick %d, var[0] = %d, want tick 1 and 5", interp.Tick, interp.State.Variables[0])
+	}
+}
+
+func TestSleepWaitsForTicks(t *testing.T) {
+	interp := newTestInterpreter(t, [][]byte{cutChg, sleep(2), cutChg, evtEnd})
+	if _, err := interp.StartThread(0); err != nil {
+		t.Fatal(err)
+	}
+	runToEnd(t, interp)
+
+	if len(interp.Events) != 2 {
+		t.Fatalf("got %d events, want 2", len(interp.Events))
+	}
+	if first, second := interp.Events[0].Tick, interp.Events[1].Tick; first != 0 || second != 2 {
+		t.Errorf("events at ticks %d and %d, want 0 and 2", first, second)
+	}
+}
+
+func TestGosubReturns(t *testing.T) {
+	tests := []struct {
+		name       string
+		subroutine [][]byte
+	}{
+		{"EvtEnd", [][]byte{save(0, 5), evtEnd}},
+		{"GosubReturn", [][]byte{save(0, 5), gosubReturn, save(0, 7), evtEnd}},
+	}
+	for _, test := range tests {
+		interp := newTestInterpreter(t,
+			[][]byte{{fileio.OP_GOSUB, 1}, calc(CALC_MUL, 0, 3), evtEnd},
+			test.subroutine,
+		)
+		thread, err := interp.StartThread(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Gosub and the first instruction of the subroutine
+		for i := 0; i < 2; i++ {
+			if _, err := interp.Step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if thread.CallDepth() != 1 {
+			t.Errorf("%s: call depth %d inside the subroutine, want 1", test.name, thread.CallDepth())
+		}
+		runToEnd(t, interp)
+		if thread.CallDepth() != 0 || interp.State.Variables[0] != 15 {
+			t.Errorf("%s: call depth %d, var[0] = %d, want 0 and 15", test.name, thread.CallDepth(), interp.State.Variables[0])
+		}
+	}
+}
+
+func TestConditions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []byte
+		setup      func(state *State)
+		want       int16
+	}{
+		{"bit set", checkBit(1, 5, 1), func(state *State) { state.SetBit(1, 5, true) }, 1},
+		{"bit not set", checkBit(1, 5, 1), func(state *State) {}, 2},
+		{"bit cleared", checkBit(1, 5, 0), func(state *State) {}, 1},
+		{"compare passes", compareVar(3, fileio.CMP_GE, 4), func(state *State) { state.Variables[3] = 4 }, 1},
+		{"compare fails", compareVar(3, fileio.CMP_GE, 4), func(state *State) { state.Variables[3] = 3 }, 2},
+		{
+			"second condition fails",
+			bytes.Join([][]byte{checkBit(1, 5, 1), checkBit(1, 6, 1)}, nil),
+			func(state *State) { state.SetBit(1, 5, true) },
+			2,
+		},
+	}
+	for _, test := range tests {
+		interp := newTestInterpreter(t, [][]byte{ifElse(test.conditions, save(0, 1), save(0, 2)), setBit(2, 0, fileio.SET_BIT_SET), evtEnd})
+		test.setup(interp.State)
+		if _, err := interp.StartThread(0); err != nil {
+			t.Fatal(err)
+		}
+		runToEnd(t, interp)
+
+		if got := interp.State.Variables[0]; got != test.want {
+			t.Errorf("%s: var[0] = %d, want %d", test.name, got, test.want)
+		}
+		if !interp.State.Bit(2, 0) {
+			t.Errorf("%s: the instruction after the if block did not run", test.name)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package interpreter
+
+// Game state that is read and written by the scripts
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+const (
+	NumBitArrays   = 256 // BitArray is a byte in CheckBit and SetBit
+	BitArraySize   = 256 // BitNumber is a byte in CheckBit and SetBit
+	NumVariables   = 256 // VarId is a byte in Save, Copy, Calc and Compare
+	NumWorkMembers = 256 // MemberIndex is a byte in MemberSet and MemberCmp
+)
+
+// Operations of the CALC (0x26) and CALC2 (0x27) instructions
+const (
+	CALC_ADD = 0  // +
+	CALC_SUB = 1  // -
+	CALC_MUL = 2  // *
+	CALC_DIV = 3  // /
+	CALC_MOD = 4  // %
+	CALC_OR  = 5  // |
+	CALC_AND = 6  // &
+	CALC_XOR = 7  // ^
+	CALC_NOT = 8  // ~ of the operand
+	CALC_SHL = 9  // <<
+	CALC_SHR = 10 // >> without sign
+	CALC_SAR = 11 // >> with sign
+)
+
+// Work is the object selected with WorkSet that the member instructions apply to
+type Work struct {
+	Component uint8 // e.g. player, enemy or object
+	Index     uint8
+}
+
+// State holds the flags, variables and objects of the game that the scripts can change
+type State struct {
+	// The bits are stored in 32 bit words starting with the most significant bit, like in the game
+	Flags     [NumBitArrays][BitArraySize / 32]uint32
+	Variables [NumVariables]int16
+	Members   map[Work]*[NumWorkMembers]int16
+	Items     map[uint8]bool // items in the inventory, checked by KeepItemCk
+}
+
+func NewState() *State {
+	return &State{
+		Members: make(map[Work]*[NumWorkMembers]int16),
+		Items:   make(map[uint8]bool),
+	}
+}
+
+// Bit returns a flag of a bit array
+func (state *State) Bit(bitArray uint8, bitNumber uint8) bool {
+	return state.Flags[bitArray][bitNumber/32]&(0x80000000>>(bitNumber%32)) != 0
+}
+
+// SetBit changes a flag of a bit array
+func (state *State) SetBit(bitArray uint8, bitNumber uint8, value bool) {
+	mask := uint32(0x80000000) >> (bitNumber % 32)
+	if value {
+		state.Flags[bitArray][bitNumber/32] |= mask
+	} else {
+		state.Flags[bitArray][bitNumber/32] &^= mask
+	}
+}
+
+// Member returns a member of the work object, e.g. its position or animation
+func (state *State) Member(work Work, memberIndex uint8) int16 {
+	if members, exists := state.Members[work]; exists {
+		return members[memberIndex]
+	}
+	return 0
+}
+
+// SetMember changes a member of the work object
+func (state *State) SetMember(work Work, memberIndex uint8, value int16) {
+	members, exists := state.Members[work]
+	if !exists {
+		members = &[NumWorkMembers]int16{}
+		state.Members[work] = members
+	}
+	members[memberIndex] = value
+}
+
+// Apply changes the state with an assignment, so that scripts can be simulated
+// for a later visit of a room. The assignments are
+//
+//	bit 1:5      set bit 5 of bit array 1
+//	bit 1:5=0    clear bit 5 of bit array 1
+//	var 3=2      set variable 3 to 2
+//	item 23      add item 23 to the inventory
+func (state *State) Apply(assignment string) error {
+	kind, value, _ := strings.Cut(strings.TrimSpace(assignment), " ")
+	value = strings.ReplaceAll(value, " ", "")
+	switch kind {
+	case "bit":
+		bit, bitValue, hasValue := strings.Cut(value, "=")
+		arrayText, numberText, found := strings.Cut(bit, ":")
+		if !found {
+			return fmt.Errorf("expected bit array:number but got %q", bit)
+		}
+		bitArray, err := strconv.ParseUint(arrayText, 0, 8)
+		if err != nil {
+			return fmt.Errorf("invalid bit array %q", arrayText)
+		}
+		bitNumber, err := strconv.ParseUint(numberText, 0, 8)
+		if err != nil {
+			return fmt.Errorf("invalid bit number %q", numberText)
+		}
+		state.SetBit(uint8(bitArray), uint8(bitNumber), !hasValue || bitValue != "0")
+	case "var":
+		varText, valueText, found := strings.Cut(value, "=")
+		if !found {
+			return fmt.Errorf("expected var id=value but got %q", value)
+		}
+		varId, err := strconv.ParseUint(varText, 0, 8)
+		if err != nil {
+			return fmt.Errorf("invalid variable %q", varText)
+		}
+		number, err := strconv.ParseInt(valueText, 0, 16)
+		if err != nil {
+			return fmt.Errorf("invalid value %q", valueText)
+		}
+		state.Variables[varId] = int16(number)
+	case "item":
+		itemId, err := strconv.ParseUint(value, 0, 8)
+		if err != nil {
+			return fmt.Errorf("invalid item %q", value)
+		}
+		state.Items[uint8(itemId)] = true
+	default:
+		return fmt.Errorf("expected bit, var or item but got %q", assignment)
+	}
+	return nil
+}
+
+// String lists the flags that are set, the variables that are not zero and the items in the inventory
+func (state *State) String() string {
+	var builder strings.Builder
+	for bitArray := 0; bitArray < NumBitArrays; bitArray++ {
+		for bitNumber := 0; bitNumber < BitArraySize; bitNumber++ {
+			if state.Bit(uint8(bitArray), uint8(bitNumber)) {
+				builder.WriteString(fmt.Sprintf("bit %d:%d\n", bitArray, bitNumber))
+			}
+		}
+	}
+	for varId, value := range state.Variables {
+		if value != 0 {
+			builder.WriteString(fmt.Sprintf("var %d=%d\n", varId, value))
+		}
+	}
+	for itemId := 0; itemId < 256; itemId++ {
+		if state.Items[uint8(itemId)] {
+			builder.WriteString(fmt.Sprintf("item %d\n", itemId))
+		}
+	}
+	return builder.String()
+}
+
+// compare applies a comparison operation of Compare and MemberCmp
+func compare(operation uint8, value int16, other int16) bool {
+	switch operation {
+	case fileio.CMP_EQ:
+		return value == other
+	case fileio.CMP_GT:
+		return value > other
+	case fileio.CMP_GE:
+		return value >= other
+	case fileio.CMP_LT:
+		return value < other
+	case fileio.CMP_LE:
+		return value <= other
+	case fileio.CMP_NE:
+		return value != other
+	case fileio.CMP_AND:
+		return value&other != 0
+	}
+	return false
+}
+
+// calculate applies an arithmetic operation of Calc and Calc2.
+// Division by zero and unknown operations leave the value unchanged.
+func calculate(operation uint8, value int16, operand int16) int16 {
+	switch operation {
+	case CALC_ADD:
+		return value + operand
+	case CALC_SUB:
+		return value - operand
+	case CALC_MUL:
+		return value * operand
+	case CALC_DIV:
+		if operand != 0 {
+			return value / operand
+		}
+	case CALC_MOD:
+		if operand != 0 {
+			return value % operand
+		}
+	case CALC_OR:
+		return value | operand
+	case CALC_AND:
+		return value & operand
+	case CALC_XOR:
+		return value ^ operand
+	case CALC_NOT:
+		return ^operand
+	case CALC_SHL:
+		return value << uint16(operand)
+	case CALC_SHR:
+		return int16(uint16(value) >> uint16(operand))
+	case CALC_SAR:
+		return value >> uint16(operand)
+	}
+	return value
+}
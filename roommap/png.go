@@ -0,0 +1,112 @@
+package roommap
+
+// Renders the map into an image
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+const (
+	imageMargin = 20  // empty space around the shapes in pixels
+	strokeWidth = 1.5 // width of the outlines in pixels
+)
+
+// RenderImage draws the map from above into an image of the given width.
+// The x axis points right and the z axis points up, the same as in WriteSVG.
+func RenderImage(roomMap *Map, width int) *image.RGBA {
+	bounds := roomMap.Bounds()
+	scale := float64(width-2*imageMargin) / math.Max(math.Max(bounds.Width(), bounds.Depth()), 1)
+	height := int(math.Ceil(bounds.Depth()*scale)) + 2*imageMargin
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	toPixel := func(point Point) (float32, float32) {
+		return float32(imageMargin + (point.X-bounds.MinX)*scale), float32(imageMargin + (bounds.MaxZ-point.Z)*scale)
+	}
+
+	rasterizer := vector.NewRasterizer(width, height)
+	for _, shape := range roomMap.Shapes {
+		if len(shape.Points) == 0 {
+			continue
+		}
+		style := layerStyles[shape.Layer]
+
+		rasterizer.Reset(width, height)
+		for i, point := range shape.Points {
+			x, y := toPixel(point)
+			if i == 0 {
+				rasterizer.MoveTo(x, y)
+			} else {
+				rasterizer.LineTo(x, y)
+			}
+		}
+		rasterizer.ClosePath()
+		rasterizer.Draw(img, img.Bounds(), image.NewUniform(style.fill), image.Point{})
+
+		rasterizer.Reset(width, height)
+		for i := range shape.Points {
+			x0, y0 := toPixel(shape.Points[i])
+			x1, y1 := toPixel(shape.Points[(i+1)%len(shape.Points)])
+			addLine(rasterizer, x0, y0, x1, y1)
+		}
+		rasterizer.Draw(img, img.Bounds(), image.NewUniform(style.stroke), image.Point{})
+	}
+
+	// Labels are drawn last so that they are not covered by other shapes
+	for _, shape := range roomMap.Shapes {
+		if shape.Label == "" || len(shape.Points) == 0 {
+			continue
+		}
+		x, y := toPixel(shape.Center())
+		drawLabel(img, shape.Label, int(x), int(y), layerStyles[shape.Layer].stroke)
+	}
+	return img
+}
+
+// WritePNG draws the map into a PNG image of the given width
+func WritePNG(w io.Writer, roomMap *Map, width int) error {
+	return png.Encode(w, RenderImage(roomMap, width))
+}
+
+// addLine adds a line segment as a thin rectangle to the rasterizer path
+func addLine(rasterizer *vector.Rasterizer, x0, y0, x1, y1 float32) {
+	dx, dy := x1-x0, y1-y0
+	length := float32(math.Hypot(float64(dx), float64(dy)))
+	if length == 0 {
+		return
+	}
+	nx, ny := -dy/length*strokeWidth/2, dx/length*strokeWidth/2
+
+	rasterizer.MoveTo(x0+nx, y0+ny)
+	rasterizer.LineTo(x1+nx, y1+ny)
+	rasterizer.LineTo(x1-nx, y1-ny)
+	rasterizer.LineTo(x0-nx, y0-ny)
+	rasterizer.ClosePath()
+}
+
+// drawLabel draws the text centered on the position
+func drawLabel(img draw.Image, text string, x, y int, textColor color.Color) {
+	face := basicfont.Face7x13
+	drawer := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(textColor),
+		Face: face,
+	}
+	textWidth := drawer.MeasureString(text)
+	drawer.Dot = fixed.Point26_6{
+		X: fixed.I(x) - textWidth/2,
+		Y: fixed.I(y) + face.Metrics().Ascent/2,
+	}
+	drawer.DrawString(text)
+}
@@ -0,0 +1,177 @@
+// Package roommap draws the floor plan of a room as seen from above.
+//
+// Every shape is stored as a polygon in world coordinates, the same x/z space
+// used by the script instructions such as AotSet and PosSet, so that collision
+// boundaries and script objects line up when they are drawn together.
+package roommap
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// circleSegments is the number of line segments used to approximate a full circle
+const circleSegments = 32
+
+// Layer groups shapes that are drawn with the same style
+type Layer int
+
+const (
+	LayerCollision Layer = iota
+	LayerAot             // AotSet, AotSet4p
+	LayerDoor            // DoorAotSet, DoorAotSet4p
+	LayerItem            // ItemAotSet, ItemAotSet4p
+	LayerEnemy           // SceEmSet
+	LayerObject          // ObjModelSet
+)
+
+// LayerName maps layers to the names used in exported files
+var LayerName = map[Layer]string{
+	LayerCollision: "collision",
+	LayerAot:       "aot",
+	LayerDoor:      "door",
+	LayerItem:      "item",
+	LayerEnemy:     "enemy",
+	LayerObject:    "object",
+}
+
+// Point is a position on the floor in world coordinates
+type Point struct {
+	X, Z float64
+}
+
+// Source is the script line that created a shape
+type Source struct {
+	ScriptFile string // name of the script file, e.g. sub0.scd
+	Line       int    // index of the instruction in the script file
+}
+
+// Shape is a polygon drawn on the map
+type Shape struct {
+	Layer  Layer
+	Class  string  // more specific style within the layer, e.g. the collision shape name
+	Points []Point // outline of the shape
+	Label  string  // short text drawn next to the shape
+	Title  string  // longer description shown as a tooltip
+	Source *Source // script line that created the shape, nil for room data
+}
+
+// Center returns the average of the points of the shape
+func (shape Shape) Center() Point {
+	center := Point{}
+	for _, point := range shape.Points {
+		center.X += point.X
+		center.Z += point.Z
+	}
+	center.X /= float64(len(shape.Points))
+	center.Z /= float64(len(shape.Points))
+	return center
+}
+
+// Map is the floor plan of a room
+type Map struct {
+	Shapes []Shape
+}
+
+// Bounds is the area covered by the shapes of a map
+type Bounds struct {
+	MinX, MinZ, MaxX, MaxZ float64
+}
+
+// Width returns the size of the area along the x axis
+func (b Bounds) Width() float64 {
+	return b.MaxX - b.MinX
+}
+
+// Depth returns the size of the area along the z axis
+func (b Bounds) Depth() float64 {
+	return b.MaxZ - b.MinZ
+}
+
+// Bounds returns the area covered by all shapes of the map
+func (m *Map) Bounds() Bounds {
+	if len(m.Shapes) == 0 {
+		return Bounds{}
+	}
+
+	bounds := Bounds{MinX: math.Inf(1), MinZ: math.Inf(1), MaxX: math.Inf(-1), MaxZ: math.Inf(-1)}
+	for _, shape := range m.Shapes {
+		for _, point := range shape.Points {
+			bounds.MinX = math.Min(bounds.MinX, point.X)
+			bounds.MinZ = math.Min(bounds.MinZ, point.Z)
+			bounds.MaxX = math.Max(bounds.MaxX, point.X)
+			bounds.MaxZ = math.Max(bounds.MaxZ, point.Z)
+		}
+	}
+	return bounds
+}
+
+// NewCollisionMap creates a map of the collision boundaries of a room
+func NewCollisionMap(scaOutput *fileio.SCAOutput) *Map {
+	roomMap := &Map{}
+	roomMap.AddCollision(scaOutput)
+	return roomMap
+}
+
+// AddCollision adds a shape for every collision boundary of the room
+func (m *Map) AddCollision(scaOutput *fileio.SCAOutput) {
+	for i, element := range scaOutput.Elements {
+		shapeName, exists := fileio.SCAShapeName[element.Shape()]
+		if !exists {
+			shapeName = fmt.Sprintf("Shape%d", element.Shape())
+		}
+
+		m.Shapes = append(m.Shapes, Shape{
+			Layer:  LayerCollision,
+			Class:  shapeName,
+			Points: collisionOutline(element),
+			Title: fmt.Sprintf("%d: %s X=%d, Z=%d, Width=%d, Depth=%d, Type=0x%x, Floor=0x%x",
+				i, shapeName, element.X, element.Z, element.Width, element.Depth, element.Type, element.Floor),
+		})
+	}
+}
+
+// collisionOutline converts a collision boundary to a polygon
+func collisionOutline(element fileio.SCAElement) []Point {
+	x0, z0 := float64(element.X), float64(element.Z)
+	x1, z1 := x0+float64(element.Width), z0+float64(element.Depth)
+	corners := []Point{{x0, z0}, {x1, z0}, {x1, z1}, {x0, z1}}
+
+	switch element.Shape() {
+	case fileio.SCA_SHAPE_TRIANGLE_1:
+		return []Point{corners[0], corners[1], corners[3]}
+	case fileio.SCA_SHAPE_TRIANGLE_2:
+		return []Point{corners[0], corners[1], corners[2]}
+	case fileio.SCA_SHAPE_TRIANGLE_3:
+		return []Point{corners[1], corners[2], corners[3]}
+	case fileio.SCA_SHAPE_TRIANGLE_4:
+		return []Point{corners[0], corners[2], corners[3]}
+	case fileio.SCA_SHAPE_RHOMBUS:
+		centerX, centerZ := (x0+x1)/2, (z0+z1)/2
+		return []Point{{centerX, z0}, {x1, centerZ}, {centerX, z1}, {x0, centerZ}}
+	case fileio.SCA_SHAPE_CIRCLE:
+		return arc((x0+x1)/2, (z0+z1)/2, (x1-x0)/2, (z1-z0)/2, 0, 2*math.Pi)
+	case fileio.SCA_SHAPE_RECT_ROUNDED_X:
+		radius := (z1 - z0) / 2
+		outline := arc(x1-radius, z0+radius, radius, radius, -math.Pi/2, math.Pi/2)
+		return append(outline, arc(x0+radius, z0+radius, radius, radius, math.Pi/2, 3*math.Pi/2)...)
+	case fileio.SCA_SHAPE_RECT_ROUNDED_Z:
+		radius := (x1 - x0) / 2
+		outline := arc(x0+radius, z1-radius, radius, radius, 0, math.Pi)
+		return append(outline, arc(x0+radius, z0+radius, radius, radius, math.Pi, 2*math.Pi)...)
+	}
+	return corners
+}
+
+// arc returns the points of an elliptical arc between two angles in radians
+func arc(centerX, centerZ, radiusX, radiusZ, startAngle, endAngle float64) []Point {
+	segments := int(math.Ceil(circleSegments * (endAngle - startAngle) / (2 * math.Pi)))
+	points := make([]Point, 0, segments+1)
+	for i := 0; i <= segments; i++ {
+		angle := startAngle + (endAngle-startAngle)*float64(i)/float64(segments)
+		points = append(points, Point{centerX + radiusX*math.Cos(angle), centerZ + radiusZ*math.Sin(angle)})
+	}
+	return points
+}
@@ -0,0 +1,125 @@
+package roommap
+
+// Adds the objects placed by the room scripts to the map
+
+import (
+	"fmt"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// markerSize is the size of the square drawn for objects that only have a position
+const markerSize = 400
+
+// NewRoomMap creates a map of the collision boundaries and every object placed by the room scripts
+func NewRoomMap(rdtOutput *fileio.RDTOutput) *Map {
+	roomMap := NewCollisionMap(rdtOutput.CollisionData)
+	roomMap.AddScriptObjects(fileio.SplitRDTScripts(rdtOutput), rdtOutput.Opcodes)
+	return roomMap
+}
+
+// AddScriptObjects adds a shape for every AOT, door, item, enemy and object placed by the scripts.
+// The scripts are decoded with the opcodes of their game.
+func (m *Map) AddScriptObjects(scriptFiles map[string][][]byte, opcodes *fileio.OpcodeSet) {
+	for _, filename := range fileio.SortedScriptFilenames(scriptFiles) {
+		for line, lineBytes := range scriptFiles[filename] {
+			instruction, err := opcodes.DecodeInstruction(0, lineBytes)
+			if err != nil {
+				continue
+			}
+			shape, ok := scriptObjectShape(instruction)
+			if !ok {
+				continue
+			}
+			shape.Source = &Source{ScriptFile: filename, Line: line}
+			shape.Title = fmt.Sprintf("%s:%d %s%s", filename, line+1, instruction.Name(), fileio.GetOpcodeSignature(instruction))
+			m.Shapes = append(m.Shapes, shape)
+		}
+	}
+}
+
+// ScriptObjects returns the shapes that were created by script instructions
+func (m *Map) ScriptObjects() []Shape {
+	shapes := make([]Shape, 0)
+	for _, shape := range m.Shapes {
+		if shape.Source != nil {
+			shapes = append(shapes, shape)
+		}
+	}
+	return shapes
+}
+
+func rectangle(x, z, width, depth int16) []Point {
+	x0, z0 := float64(x), float64(z)
+	x1, z1 := x0+float64(width), z0+float64(depth)
+	return []Point{{x0, z0}, {x1, z0}, {x1, z1}, {x0, z1}}
+}
+
+func quad(x1, z1, x2, z2, x3, z3, x4, z4 int16) []Point {
+	return []Point{{float64(x1), float64(z1)}, {float64(x2), float64(z2)}, {float64(x3), float64(z3)}, {float64(x4), float64(z4)}}
+}
+
+func marker(x, z int16) []Point {
+	return rectangle(x-markerSize/2, z-markerSize/2, markerSize, markerSize)
+}
+
+// scriptObjectShape converts an instruction that places an object in the room into a shape
+func scriptObjectShape(instruction fileio.Instruction) (Shape, bool) {
+	switch instr := instruction.Decoded().(type) {
+	case fileio.ScriptInstrAotSet:
+		return Shape{
+			Layer:  LayerAot,
+			Points: rectangle(instr.X, instr.Z, instr.Width, instr.Depth),
+			Label:  fmt.Sprintf("AOT %d", instr.Aot),
+		}, true
+	case fileio.ScriptInstrAotSet4p:
+		return Shape{
+			Layer:  LayerAot,
+			Points: quad(instr.X1, instr.Z1, instr.X2, instr.Z2, instr.X3, instr.Z3, instr.X4, instr.Z4),
+			Label:  fmt.Sprintf("AOT %d", instr.Aot),
+		}, true
+	case fileio.ScriptInstrDoorAotSet:
+		return Shape{
+			Layer:  LayerDoor,
+			Points: rectangle(instr.X, instr.Z, instr.Width, instr.Depth),
+			Label:  "Door to room " + fileio.RoomId(instr.Stage, instr.Room),
+		}, true
+	case fileio.ScriptInstrDoorAotSet4p:
+		return Shape{
+			Layer:  LayerDoor,
+			Points: quad(instr.X1, instr.Z1, instr.X2, instr.Z2, instr.X3, instr.Z3, instr.X4, instr.Z4),
+			Label:  "Door to room " + fileio.RoomId(instr.Stage, instr.Room),
+		}, true
+	case fileio.ScriptInstrItemAotSet:
+		return Shape{
+			Layer:  LayerItem,
+			Points: rectangle(instr.X, instr.Z, instr.Width, instr.Depth),
+			Label:  fmt.Sprintf("Item %d x%d", instr.ItemId, instr.Amount),
+		}, true
+	case fileio.ScriptInstrItemAotSet4p:
+		return Shape{
+			Layer:  LayerItem,
+			Points: quad(instr.X1, instr.Z1, instr.X2, instr.Z2, instr.X3, instr.Z3, instr.X4, instr.Z4),
+			Label:  fmt.Sprintf("Item %d x%d", instr.ItemId, instr.Amount),
+		}, true
+	case fileio.ScriptInstrSceEmSet:
+		return Shape{
+			Layer:  LayerEnemy,
+			Points: marker(instr.X, instr.Z),
+			Label:  fmt.Sprintf("Enemy %d", instr.Type),
+		}, true
+	case fileio.ScriptInstrObjModelSet:
+		x, z := instr.Position[0], instr.Position[2]
+		points := marker(x, z)
+		if instr.Dimensions[0] > 0 && instr.Dimensions[2] > 0 {
+			width, depth := int16(instr.Dimensions[0]), int16(instr.Dimensions[2])
+			points = rectangle(x-width/2, z-depth/2, width, depth)
+		}
+		return Shape{
+			Layer:  LayerObject,
+			Points: points,
+			Label:  fmt.Sprintf("Object %d", instr.ObjectId),
+		}, true
+	}
+	return Shape{}, false
+}
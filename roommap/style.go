@@ -0,0 +1,27 @@
+package roommap
+
+// Colors of the map layers, shared by the SVG and PNG exports
+
+import (
+	"fmt"
+	"image/color"
+)
+
+type layerStyle struct {
+	fill   color.NRGBA
+	stroke color.NRGBA
+}
+
+var layerStyles = map[Layer]layerStyle{
+	LayerCollision: {fill: color.NRGBA{0x80, 0x80, 0x80, 0x5a}, stroke: color.NRGBA{0x40, 0x40, 0x40, 0xff}},
+	LayerAot:       {fill: color.NRGBA{0xf0, 0xc0, 0x20, 0x50}, stroke: color.NRGBA{0xa0, 0x80, 0x00, 0xff}},
+	LayerDoor:      {fill: color.NRGBA{0x30, 0x70, 0xe0, 0x60}, stroke: color.NRGBA{0x10, 0x40, 0xa0, 0xff}},
+	LayerItem:      {fill: color.NRGBA{0x30, 0xb0, 0x40, 0x60}, stroke: color.NRGBA{0x10, 0x70, 0x20, 0xff}},
+	LayerEnemy:     {fill: color.NRGBA{0xe0, 0x30, 0x30, 0x80}, stroke: color.NRGBA{0x90, 0x10, 0x10, 0xff}},
+	LayerObject:    {fill: color.NRGBA{0x90, 0x50, 0xc0, 0x60}, stroke: color.NRGBA{0x60, 0x20, 0x90, 0xff}},
+}
+
+// cssColor converts a color to a CSS color with opacity
+func cssColor(c color.NRGBA) string {
+	return fmt.Sprintf("rgba(%d, %d, %d, %.2f)", c.R, c.G, c.B, float64(c.A)/255)
+}
@@ -0,0 +1,88 @@
+package roommap
+
+// Exports the map as an SVG image
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// svgMargin is the empty space around the shapes in world units
+const svgMargin = 500
+
+// svgCollisionStyle highlights collision boundaries that the player can walk or climb on
+const svgCollisionStyle = `
+    .collision.Slope, .collision.Stairs { fill: rgba(192, 160, 96, 0.5); }
+    .collision.ClimbUp, .collision.JumpDown, .collision.ClimbHigh, .collision.JumpDownHigh { fill: rgba(96, 160, 192, 0.5); }
+`
+
+// svgStyle returns the style sheet of the exported image, the classes are the layer names
+func svgStyle() string {
+	var builder strings.Builder
+	builder.WriteString("\n    polygon { stroke-width: 1; vector-effect: non-scaling-stroke; }\n")
+	builder.WriteString("    text { font-family: sans-serif; }\n")
+	for layer := LayerCollision; layer <= LayerObject; layer++ {
+		style := layerStyles[layer]
+		builder.WriteString(fmt.Sprintf("    polygon.%s { fill: %s; stroke: %s; }\n",
+			LayerName[layer], cssColor(style.fill), cssColor(style.stroke)))
+		builder.WriteString(fmt.Sprintf("    text.%s { fill: %s; }\n", LayerName[layer], cssColor(style.stroke)))
+	}
+	builder.WriteString(svgCollisionStyle)
+	return builder.String()
+}
+
+// WriteSVG draws the map from above. The x axis points right and the z axis points up.
+func WriteSVG(w io.Writer, roomMap *Map) error {
+	writer := bufio.NewWriter(w)
+	bounds := roomMap.Bounds()
+
+	// The z axis is flipped because the y axis of the image points down
+	minX := bounds.MinX - svgMargin
+	minY := -bounds.MaxZ - svgMargin
+	width := bounds.Width() + 2*svgMargin
+	height := bounds.Depth() + 2*svgMargin
+	fontSize := svgFontSize(width, height)
+
+	fmt.Fprintf(writer, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="%g %g %g %g" width="%d" height="%d">`+"\n",
+		minX, minY, width, height, 1000, int(1000*height/width))
+	fmt.Fprintf(writer, "<style>%s</style>\n", svgStyle())
+	fmt.Fprintf(writer, `<rect x="%g" y="%g" width="%g" height="%g" fill="white"/>`+"\n", minX, minY, width, height)
+
+	for _, shape := range roomMap.Shapes {
+		points := make([]string, 0, len(shape.Points))
+		for _, point := range shape.Points {
+			points = append(points, fmt.Sprintf("%g,%g", point.X, -point.Z))
+		}
+
+		class := LayerName[shape.Layer]
+		if shape.Class != "" {
+			class += " " + shape.Class
+		}
+		fmt.Fprintf(writer, `<polygon class="%s" points="%s">`, html.EscapeString(class), strings.Join(points, " "))
+		if shape.Title != "" {
+			fmt.Fprintf(writer, "<title>%s</title>", html.EscapeString(shape.Title))
+		}
+		fmt.Fprintln(writer, "</polygon>")
+
+		if shape.Label != "" && len(shape.Points) > 0 {
+			center := shape.Center()
+			fmt.Fprintf(writer, `<text class="%s" x="%g" y="%g" font-size="%g" text-anchor="middle">%s</text>`+"\n",
+				html.EscapeString(class), center.X, -center.Z, fontSize, html.EscapeString(shape.Label))
+		}
+	}
+
+	fmt.Fprintln(writer, "</svg>")
+	return writer.Flush()
+}
+
+// svgFontSize returns a font size in world units that stays readable at the default image size
+func svgFontSize(width, height float64) float64 {
+	size := width
+	if height > size {
+		size = height
+	}
+	return size / 80
+}
@@ -5,12 +5,18 @@ import (
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/driver/desktop"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/catalogue"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/interpreter"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/roommap"
 )
 
 // App represents the whole application with all its windows, widgets and functions
@@ -20,9 +26,34 @@ type App struct {
 
 	mainModKey desktop.Modifier
 
-	split               *container.Split
-	rawScriptData       *widget.Entry
-	convertedScriptCode *widget.Entry
+	split                *container.Split
+	rawScriptData        *widget.Entry
+	convertedScriptCode  *widget.Entry
+	decompiledScriptCode *widget.Entry
+	messageText          *widget.Entry
+	cameraText           *widget.Entry
+	codeTabs             *container.AppTabs
+
+	mapImage      *canvas.Image
+	mapObjectList *widget.List
+	mapObjects    []roommap.Shape
+
+	debugger          *interpreter.Interpreter
+	debugInitialState *widget.Entry
+	debugThreadList   *widget.List
+	debugStateText    *widget.Entry
+
+	problemList *widget.List
+	problems    []*fileio.Diagnostic
+
+	flagIndex    *catalogue.FlagIndex
+	flagIndexDir string // directory of the rooms in flagIndex
+
+	rdtOutput             *fileio.RDTOutput
+	roomFilename          string
+	scriptFilenames       []string
+	scriptProgramCounters map[string][]int // program counter of every line of each script file
+	currentScriptFile     string
 
 	fileListBar *widget.List
 	statusBar   *fyne.Container
@@ -39,6 +70,18 @@ func (a *App) init() {
 		a.app.Settings().SetTheme(theme.DarkTheme())
 	}
 
+	// rooms with errors are rejected or loaded without the broken parts
+	fileio.StrictParsing = a.app.Preferences().BoolWithFallback(strictParsingPreference, false)
+
+	// game of the rooms, detected for every room if none is selected
+	a.loadSavedGame()
+
+	// names added to the parameters of the scripts
+	a.loadSavedSymbolFiles()
+
+	// opcode definitions that replace the built-in tables
+	a.loadSavedOpcodeSpecFiles()
+
 	// show/hide statusbar
 	if a.app.Preferences().BoolWithFallback("statusBarVisible", true) == false {
 		a.statusBar.Hide()
@@ -50,12 +93,15 @@ func (a *App) loadStatusBar() *fyne.Container {
 		widget.NewSeparator(),
 		container.NewHBox(
 			layout.NewSpacer(),
+			widget.NewLabel(a.gameName()),
+			widget.NewLabel(a.problemSummary()),
 		))
 	return a.statusBar
 }
 
-func (a *App) loadFileList(filenames []string, scriptFiles map[string][][]byte) *widget.List {
+func (a *App) loadFileList(filenames []string, scriptFiles map[string][][]byte, decompiledFiles map[string]string, rdtOutput *fileio.RDTOutput) *widget.List {
 	data := filenames
+	a.scriptFilenames = filenames
 
 	icon := widget.NewIcon(nil)
 	label := widget.NewLabel("Select An Item From The List")
@@ -74,10 +120,12 @@ func (a *App) loadFileList(filenames []string, scriptFiles map[string][][]byte)
 	list.OnSelected = func(id widget.ListItemID) {
 		label.SetText(data[id])
 		icon.SetResource(theme.DocumentIcon())
+		a.currentScriptFile = filenames[id]
 
 		if scriptFiles != nil {
-			a.rawScriptData.SetText(convertRawScriptInstructionsToString(scriptFiles[filenames[id]]))
-			a.convertedScriptCode.SetText(convertScriptInstructionsToCode(scriptFiles[filenames[id]]))
+			a.rawScriptData.SetText(fileio.ConvertRawScriptInstructionsToString(scriptFiles[filenames[id]]))
+			a.convertedScriptCode.SetText(fileio.ConvertScriptInstructionsToCode(scriptFiles[filenames[id]], rdtOutput))
+			a.decompiledScriptCode.SetText(decompiledFiles[filenames[id]])
 		}
 	}
 	list.OnUnselected = func(id widget.ListItemID) {
@@ -100,10 +148,24 @@ func (a *App) loadMainUI() fyne.CanvasObject {
 		a.mainModKey = desktop.ControlModifier
 	}
 
+	strictParsingItem := fyne.NewMenuItem("Strict Parsing", nil)
+	strictParsingItem.Checked = fileio.StrictParsing
+	strictParsingItem.Action = func() { a.toggleStrictParsing(strictParsingItem) }
+
 	// main menu
 	mainMenu := fyne.NewMainMenu(
 		fyne.NewMenu("File",
 			fyne.NewMenuItem("Open", a.openFileDialog),
+			fyne.NewMenuItemSeparator(),
+			fyne.NewMenuItem("Load Symbol File", a.openSymbolFileDialog),
+			fyne.NewMenuItem("Reset Symbols", a.resetSymbols),
+			fyne.NewMenuItem("Load Opcode Spec File", a.openOpcodeSpecFileDialog),
+			fyne.NewMenuItem("Reset Opcode Specs", a.resetOpcodeSpecs),
+			fyne.NewMenuItemSeparator(),
+			strictParsingItem,
+		),
+		fyne.NewMenu("Search",
+			fyne.NewMenuItem("Find Flag References", a.findFlagReferences),
 		),
 		fyne.NewMenu("Help",
 			fyne.NewMenuItem("About", func() {
@@ -125,12 +187,34 @@ func (a *App) loadMainUI() fyne.CanvasObject {
 	a.convertedScriptCode.Wrapping = fyne.TextWrapWord
 	a.convertedScriptCode.SetText("")
 
+	a.decompiledScriptCode = widget.NewMultiLineEntry()
+	a.decompiledScriptCode.Wrapping = fyne.TextWrapWord
+	a.decompiledScriptCode.SetText("")
+
+	a.messageText = widget.NewMultiLineEntry()
+	a.messageText.Wrapping = fyne.TextWrapWord
+	a.messageText.SetText("")
+
+	a.cameraText = widget.NewMultiLineEntry()
+	a.cameraText.TextStyle = fyne.TextStyle{Monospace: true}
+	a.cameraText.SetText("")
+
+	a.codeTabs = container.NewAppTabs(
+		container.NewTabItem("Pseudocode", a.convertedScriptCode),
+		container.NewTabItem("Decompiled", a.decompiledScriptCode),
+		container.NewTabItem("Messages", a.messageText),
+		container.NewTabItem("Cameras", a.cameraText),
+		container.NewTabItem("Map", a.loadMapView()),
+		container.NewTabItem("Debugger", a.loadDebuggerView()),
+		container.NewTabItem("Problems", a.loadProblemsView()),
+	)
+
 	a.split = container.NewHSplit(
 		a.rawScriptData,
-		a.convertedScriptCode,
+		a.codeTabs,
 	)
 	a.split.SetOffset(0.50)
-	layout := container.NewBorder(nil, a.loadStatusBar(), a.loadFileList([]string{}, nil), nil, a.split)
+	layout := container.NewBorder(nil, a.loadStatusBar(), a.loadFileList([]string{}, nil, nil, nil), nil, a.split)
 	return layout
 }
 
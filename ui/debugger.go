@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/interpreter"
+)
+
+// debugEventLines is the number of recent engine events shown in the debugger tab
+const debugEventLines = 20
+
+// loadDebuggerView creates the debugger tab with the step buttons, the state to start from,
+// the list of threads and the flags and variables of the simulated game
+func (a *App) loadDebuggerView() fyne.CanvasObject {
+	a.debugInitialState = widget.NewMultiLineEntry()
+	a.debugInitialState.SetPlaceHolder("State before the room starts, one per line, e.g.\nbit 1:5\nvar 3=2\nitem 23")
+	a.debugInitialState.SetMinRowsVisible(3)
+
+	a.debugStateText = widget.NewMultiLineEntry()
+	a.debugStateText.TextStyle = fyne.TextStyle{Monospace: true}
+
+	a.debugThreadList = widget.NewList(
+		func() int {
+			if a.debugger == nil {
+				return 0
+			}
+			return len(a.debugger.Threads)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template Object")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(formatThread(a.debugger.Threads[id]))
+		},
+	)
+	a.debugThreadList.OnSelected = func(id widget.ListItemID) {
+		a.showLocation(a.debugger.Threads[id].Location())
+	}
+
+	buttons := container.NewHBox(
+		widget.NewButton("Restart", a.restartDebugger),
+		widget.NewButton("Step (F11)", func() { a.runDebugger((*interpreter.Interpreter).Step) }),
+		widget.NewButton("Step Over (F10)", func() { a.runDebugger((*interpreter.Interpreter).StepOver) }),
+		widget.NewButton("Run To Cursor", a.runToCursor),
+		widget.NewButton("Continue (F5)", func() { a.runDebugger((*interpreter.Interpreter).Continue) }),
+		widget.NewButton("Breakpoint (F9)", a.toggleBreakpoint),
+	)
+
+	split := container.NewHSplit(a.debugThreadList, a.debugStateText)
+	split.SetOffset(0.40)
+	return container.NewBorder(container.NewVBox(buttons, a.debugInitialState), nil, nil, nil, split)
+}
+
+// resetDebugger stops the debugger when a new room was opened
+func (a *App) resetDebugger() {
+	a.debugger = nil
+	a.debugThreadList.Refresh()
+	a.debugStateText.SetText("Press Restart to run the room scripts")
+}
+
+// restartDebugger runs the init script of the room from the initial state and starts sub0 and sub1
+func (a *App) restartDebugger() {
+	if a.rdtOutput == nil {
+		return
+	}
+
+	state := interpreter.NewState()
+	for _, line := range strings.Split(a.debugInitialState.Text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := state.Apply(line); err != nil {
+			dialog.ShowError(err, a.mainWin)
+			return
+		}
+	}
+
+	// Keep the breakpoints of the previous run
+	var breakpoints map[interpreter.Location]bool
+	if a.debugger != nil {
+		breakpoints = a.debugger.Breakpoints
+	}
+	debugger, err := interpreter.NewRoom(a.rdtOutput, state)
+	if err != nil {
+		dialog.ShowError(err, a.mainWin)
+		return
+	}
+	if breakpoints != nil {
+		debugger.Breakpoints = breakpoints
+	}
+	a.debugger = debugger
+	a.refreshDebugger()
+}
+
+// runDebugger runs the interpreter with one of the step functions and shows where it stopped
+func (a *App) runDebugger(run func(*interpreter.Interpreter) (bool, error)) {
+	if a.debugger == nil {
+		a.restartDebugger()
+		if a.debugger == nil {
+			return
+		}
+	}
+	if _, err := run(a.debugger); err != nil {
+		dialog.ShowError(err, a.mainWin)
+	}
+	a.refreshDebugger()
+}
+
+func (a *App) runToCursor() {
+	location, ok := a.cursorLocation()
+	if !ok {
+		return
+	}
+	a.runDebugger(func(debugger *interpreter.Interpreter) (bool, error) {
+		return debugger.RunTo(location)
+	})
+}
+
+func (a *App) toggleBreakpoint() {
+	location, ok := a.cursorLocation()
+	if !ok {
+		return
+	}
+	if a.debugger == nil {
+		a.restartDebugger()
+		if a.debugger == nil {
+			return
+		}
+	}
+	a.debugger.ToggleBreakpoint(location)
+	a.refreshDebugger()
+}
+
+// refreshDebugger shows the threads and the state, and moves the cursor to the next instruction
+func (a *App) refreshDebugger() {
+	a.debugThreadList.Refresh()
+
+	var builder strings.Builder
+	thread, instruction, running := a.debugger.Current()
+	if running && instruction != nil {
+		builder.WriteString(fmt.Sprintf("// tick %d, next: thread %d %s%s\n", a.debugger.Tick, thread.Id,
+			instruction.Name(), fileio.GetOpcodeSignature(instruction)))
+	} else {
+		builder.WriteString(fmt.Sprintf("// tick %d, every thread has finished\n", a.debugger.Tick))
+	}
+
+	builder.WriteString("\n// state\n")
+	builder.WriteString(a.debugger.State.String())
+
+	builder.WriteString("\n// breakpoints\n")
+	breakpoints := make([]interpreter.Location, 0, len(a.debugger.Breakpoints))
+	for location := range a.debugger.Breakpoints {
+		breakpoints = append(breakpoints, location)
+	}
+	sort.Slice(breakpoints, func(i, j int) bool {
+		if breakpoints[i].Init != breakpoints[j].Init {
+			return breakpoints[i].Init
+		}
+		return breakpoints[i].ProgramCounter < breakpoints[j].ProgramCounter
+	})
+	for _, location := range breakpoints {
+		if filename, line, ok := a.scriptLine(location); ok {
+			builder.WriteString(fmt.Sprintf("%s line %d (%04x)\n", filename, line+1, location.ProgramCounter))
+		}
+	}
+
+	builder.WriteString("\n// engine events\n")
+	events := a.debugger.Events
+	for _, event := range events[max(len(events)-debugEventLines, 0):] {
+		builder.WriteString(event.String() + "\n")
+	}
+	a.debugStateText.SetText(builder.String())
+
+	if running {
+		a.showLocation(thread.Location())
+	}
+}
+
+func formatThread(thread *interpreter.Thread) string {
+	name := fmt.Sprintf("thread %d", thread.Id)
+	if thread.Id == interpreter.InitThreadId {
+		name = "init"
+	}
+	status := ""
+	switch {
+	case thread.Finished:
+		status = "finished"
+	case thread.Sleep > 0:
+		status = fmt.Sprintf("sleep %d", thread.Sleep)
+	}
+	return fmt.Sprintf("%s  %s %04x  depth %d  %s", name, thread.ScriptFilename(), thread.ProgramCounter, thread.CallDepth(), status)
+}
+
+// cursorLocation returns the instruction on the line of the cursor in the script file that is shown
+func (a *App) cursorLocation() (interpreter.Location, bool) {
+	programCounters := a.scriptProgramCounters[a.currentScriptFile]
+	row := a.rawScriptData.CursorRow
+	if row < 0 || row >= len(programCounters) {
+		return interpreter.Location{}, false
+	}
+	return interpreter.Location{Init: a.currentScriptFile == "init.scd", ProgramCounter: programCounters[row]}, true
+}
+
+// scriptLine returns the script file and line of an instruction
+func (a *App) scriptLine(location interpreter.Location) (string, int, bool) {
+	for filename, programCounters := range a.scriptProgramCounters {
+		if (filename == "init.scd") != location.Init {
+			continue
+		}
+		line := sort.SearchInts(programCounters, location.ProgramCounter)
+		if line < len(programCounters) && programCounters[line] == location.ProgramCounter {
+			return filename, line, true
+		}
+	}
+	return "", 0, false
+}
+
+// showLocation opens the script file of an instruction and moves the cursor to its line
+func (a *App) showLocation(location interpreter.Location) {
+	if filename, line, ok := a.scriptLine(location); ok {
+		a.selectScriptLine(filename, line, false)
+	}
+}
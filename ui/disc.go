@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// openDiscImage lists the rooms on a disc image and opens the selected room
+func (a *App) openDiscImage(image string) {
+	disc, err := fileio.OpenDiscImage(image)
+	if err != nil {
+		dialog.ShowError(err, a.mainWin)
+		return
+	}
+	rooms, err := disc.RoomFiles()
+	disc.Close()
+	if err != nil {
+		dialog.ShowError(err, a.mainWin)
+		return
+	}
+	if len(rooms) == 0 {
+		dialog.ShowInformation("Open Disc Image", "The disc image has no RDT files.", a.mainWin)
+		return
+	}
+
+	var roomDialog dialog.Dialog
+	list := widget.NewList(
+		func() int {
+			return len(rooms)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template Object")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(rooms[id].Path)
+		},
+	)
+	list.OnSelected = func(id widget.ListItemID) {
+		roomDialog.Hide()
+		if err := a.openRoomFile(fileio.DiscRoomPath(image, rooms[id].Path)); err != nil {
+			dialog.ShowError(err, a.mainWin)
+		}
+	}
+
+	roomDialog = dialog.NewCustom("Rooms on "+image, "Close", list, a.mainWin)
+	roomDialog.Resize(fyne.NewSize(500, 600))
+	roomDialog.Show()
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"sort"
 	"strings"
 
 	"fyne.io/fyne/v2"
@@ -12,7 +11,9 @@ import (
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/storage"
 
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/decompiler"
 	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/roommap"
 )
 
 func (a *App) openFileDialog() {
@@ -33,6 +34,10 @@ func (a *App) openFileDialog() {
 
 		// Properly handle file URI
 		filePath := reader.URI().Path()
+		if fileio.IsDiscImage(filePath) {
+			a.openDiscImage(filePath)
+			return
+		}
 		file, err := os.Open(filePath)
 		if err != nil {
 			dialog.ShowError(err, a.mainWin)
@@ -46,7 +51,7 @@ func (a *App) openFileDialog() {
 			return
 		}
 	}, a.mainWin)
-	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".rdt"}))
+	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".rdt", ".iso", ".bin", ".cue", ".img"}))
 	dialog.Show()
 }
 
@@ -57,109 +62,65 @@ func (a *App) open(file *os.File, folder bool) error {
 	if err != nil {
 		return err
 	}
-	fileLength := fi.Size()
+	return a.openRoom(io.NewSectionReader(file, int64(0), fi.Size()), file.Name())
+}
 
-	streamReader := io.NewSectionReader(file, int64(0), fileLength)
-	rdtOutput, err := fileio.LoadRDT(streamReader, fileLength)
+// openRoomFile opens an RDT file or a room on a disc image
+func (a *App) openRoomFile(filename string) error {
+	if image, path, ok := fileio.SplitDiscRoomPath(filename); ok {
+		disc, err := fileio.OpenDiscImage(image)
+		if err != nil {
+			return err
+		}
+		defer disc.Close()
+		reader, err := disc.Open(path)
+		if err != nil {
+			return err
+		}
+		return a.openRoom(reader, filename)
+	}
+
+	file, err := os.Open(filename)
 	if err != nil {
 		return err
 	}
+	return a.open(file, true)
+}
 
-	scriptFiles := splitScriptDataIntoFiles(rdtOutput.RoomScriptData)
-	// Add script from init
-	scriptFiles["init.scd"] = convertInitialScriptIntoFile(rdtOutput.InitScriptData)
-
-	filenames := make([]string, 0, len(scriptFiles))
-	for filename, _ := range scriptFiles {
-		filenames = append(filenames, filename)
+// openRoom shows the room read from the reader, the filename is used to open it again
+func (a *App) openRoom(streamReader *io.SectionReader, filename string) error {
+	rdtOutput, err := fileio.LoadRDT(streamReader, streamReader.Size())
+	if err != nil {
+		return err
 	}
-	sort.Strings(filenames)
 
-	layout := container.NewBorder(nil, a.loadStatusBar(), a.loadFileList(filenames, scriptFiles), nil, a.split)
+	scriptFiles := fileio.SplitRDTScripts(rdtOutput)
+	filenames := fileio.SortedScriptFilenames(scriptFiles)
+	decompiledFiles := decompiler.DecompileRDT(rdtOutput)
+	a.messageText.SetText(convertMessagesToString(rdtOutput))
+	a.cameraText.SetText(fileio.ConvertCamerasToString(rdtOutput.CameraPositionData) + "\n" +
+		fileio.ConvertCameraSwitchesToString(rdtOutput.CameraPositionData, rdtOutput.CameraSwitchData))
+	a.setRoomMap(roommap.NewRoomMap(rdtOutput))
+	a.rdtOutput = rdtOutput
+	a.roomFilename = filename
+	a.scriptProgramCounters = fileio.ScriptFileProgramCounters(rdtOutput)
+	a.resetDebugger()
+	a.setProblems(rdtOutput.Diagnostics)
+
+	layout := container.NewBorder(nil, a.loadStatusBar(), a.loadFileList(filenames, scriptFiles, decompiledFiles, rdtOutput), nil, a.split)
 	a.mainWin.SetContent(layout)
 
 	return nil
 }
 
-func convertInitialScriptIntoFile(scriptFile *fileio.SCDOutput) [][]byte {
-	programCounters := sortProgramCounters(scriptFile.ScriptData.Instructions)
-
-	fileLines := make([][]byte, 0)
-	for _, programCounter := range programCounters {
-		fileLines = append(fileLines, scriptFile.ScriptData.Instructions[programCounter])
-	}
-	return fileLines
-}
-
-func splitScriptDataIntoFiles(scriptFile *fileio.SCDOutput) map[string][][]byte {
-	programCounters := sortProgramCounters(scriptFile.ScriptData.Instructions)
-
-	startCounterExists := make(map[int]bool)
-	for _, start := range scriptFile.ScriptData.StartProgramCounter {
-		startCounterExists[start] = true
-	}
-
-	scriptFiles := make(map[string][][]byte)
-	fileLines := make([][]byte, 0)
-	fileIndex := 0
-	for _, programCounter := range programCounters {
-		_, ok := startCounterExists[programCounter]
-		if ok && programCounter > 0 {
-			scriptFiles[fmt.Sprintf("sub%d.scd", fileIndex)] = fileLines
-			fileIndex++
-			fileLines = make([][]byte, 0)
-		}
-
-		fileLines = append(fileLines, scriptFile.ScriptData.Instructions[programCounter])
-	}
-
-	// Add last script function
-	if len(fileLines) > 0 {
-		scriptFiles[fmt.Sprintf("sub%d.scd", fileIndex)] = fileLines
-	}
-
-	return scriptFiles
-}
-
-func convertRawScriptInstructionsToString(instructions [][]byte) string {
+func convertMessagesToString(rdtOutput *fileio.RDTOutput) string {
 	var builder strings.Builder
-	for _, lineBytes := range instructions {
-		// print out hex values
-		for i := 0; i < len(lineBytes); i++ {
-			builder.WriteString(fmt.Sprintf("%02x ", lineBytes[i]))
+	languages := []*fileio.MSGOutput{rdtOutput.Lang1MessageData, rdtOutput.Lang2MessageData}
+	for i, msgOutput := range languages {
+		builder.WriteString(fmt.Sprintf("// Lang%d%s\n", i+1, fileio.MessageLanguageNote(msgOutput)))
+		for id, message := range msgOutput.Messages {
+			builder.WriteString(fmt.Sprintf("[%d]\n%s\n\n", id, message))
 		}
-		builder.WriteString("\n")
 	}
-
 	return builder.String()
 }
-
-func convertScriptInstructionsToCode(instructions [][]byte) string {
-	var builder strings.Builder
-	for _, lineBytes := range instructions {
-		builder.WriteString(getFunctionNameFromOpcode(lineBytes[0]))
-		builder.WriteString(showParameters(lineBytes))
-		builder.WriteString("\n")
-	}
-
-	return builder.String()
-}
-
-func sortProgramCounters(instructions map[int][]byte) []int {
-	// sort script commands in order
-	programCounters := make([]int, 0, len(instructions))
-	for counter, _ := range instructions {
-		programCounters = append(programCounters, counter)
-	}
-	sort.Ints(programCounters)
-
-	return programCounters
-}
-
-func getFunctionNameFromOpcode(opcode byte) string {
-	return fileio.FunctionName[opcode]
-}
-
-func showParameters(lineBytes []byte) string {
-	return fileio.GetOpcodeSignature(lineBytes)
-}
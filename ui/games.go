@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// gamePreference stores the id of the game selected in the Game menu, empty to detect the game of every room
+const gamePreference = "game"
+
+// loadSavedGame selects the game that was selected when the viewer was closed
+func (a *App) loadSavedGame() {
+	id := a.app.Preferences().String(gamePreference)
+	if id == "" {
+		return
+	}
+	game, err := fileio.FindGame(id)
+	if err != nil {
+		a.app.Preferences().RemoveValue(gamePreference)
+		return
+	}
+	fileio.SelectedGame = game
+	if _, err := game.Opcodes(); err != nil {
+		fyne.LogError("failed to select game "+id, err)
+	}
+}
+
+// loadGameMenu creates the menu that chooses between detecting the game of a room and reading all rooms as one game
+func (a *App) loadGameMenu() *fyne.Menu {
+	games := append([]*fileio.Game{nil}, fileio.Games...)
+	gameItems := make([]*fyne.MenuItem, len(games))
+	for i, game := range games {
+		label := "Detect Automatically"
+		if game != nil {
+			label = game.Name
+		}
+		gameItems[i] = fyne.NewMenuItem(label, nil)
+		gameItems[i].Checked = game == fileio.SelectedGame
+	}
+	for i, game := range games {
+		gameItems[i].Action = func() {
+			for j, item := range gameItems {
+				item.Checked = j == i
+			}
+			a.mainWin.MainMenu().Refresh()
+			a.selectGame(game)
+		}
+	}
+
+	items := append([]*fyne.MenuItem{gameItems[0], fyne.NewMenuItemSeparator()}, gameItems[1:]...)
+	return fyne.NewMenu("Game", items...)
+}
+
+// selectGame reads the rooms as rooms of the game, or detects the game of every room if game is nil,
+// and loads the opened room again
+func (a *App) selectGame(game *fileio.Game) {
+	fileio.SelectedGame = game
+	if game == nil {
+		a.app.Preferences().RemoveValue(gamePreference)
+	} else {
+		if _, err := game.Opcodes(); err != nil {
+			dialog.ShowError(err, a.mainWin)
+			return
+		}
+		a.app.Preferences().SetString(gamePreference, game.Id)
+	}
+	a.reopenRoom()
+}
+
+// gameName returns the name of the game of the opened room for the status bar
+func (a *App) gameName() string {
+	if a.rdtOutput == nil || a.rdtOutput.Game == nil {
+		return ""
+	}
+	return a.rdtOutput.Game.Name
+}
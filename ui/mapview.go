@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/roommap"
+)
+
+// mapImageWidth is the width in pixels of the map image shown in the map tab
+const mapImageWidth = 1024
+
+// loadMapView creates the map tab with the room map on top and the list of script objects below
+func (a *App) loadMapView() fyne.CanvasObject {
+	a.mapImage = canvas.NewImageFromImage(nil)
+	a.mapImage.FillMode = canvas.ImageFillContain
+	a.mapImage.ScaleMode = canvas.ImageScaleSmooth
+
+	a.mapObjectList = widget.NewList(
+		func() int {
+			return len(a.mapObjects)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template Object")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			shape := a.mapObjects[id]
+			item.(*widget.Label).SetText(fmt.Sprintf("[%s] %s", roommap.LayerName[shape.Layer], shape.Title))
+		},
+	)
+	a.mapObjectList.OnSelected = func(id widget.ListItemID) {
+		a.showScriptLine(a.mapObjects[id].Source)
+	}
+
+	split := container.NewVSplit(a.mapImage, a.mapObjectList)
+	split.SetOffset(0.70)
+	return split
+}
+
+// setRoomMap shows the map of the room and its script objects in the map tab
+func (a *App) setRoomMap(roomMap *roommap.Map) {
+	a.mapObjects = roomMap.ScriptObjects()
+	a.mapObjectList.UnselectAll()
+	a.mapObjectList.Refresh()
+
+	a.mapImage.Image = roommap.RenderImage(roomMap, mapImageWidth)
+	a.mapImage.Refresh()
+}
+
+// showScriptLine opens the script file and moves the cursor to the line that created a map object
+func (a *App) showScriptLine(source *roommap.Source) {
+	if source == nil {
+		return
+	}
+	a.selectScriptLine(source.ScriptFile, source.Line, true)
+}
+
+// selectScriptLine opens a script file and highlights a line in the bytecode and pseudocode.
+// The pseudocode tab is shown if showCode is true.
+func (a *App) selectScriptLine(filename string, line int, showCode bool) {
+	for id, scriptFilename := range a.scriptFilenames {
+		if scriptFilename != filename {
+			continue
+		}
+		a.fileListBar.Select(id)
+
+		highlightLine(a.rawScriptData, line)
+		highlightLine(a.convertedScriptCode, line)
+		// The selection is only drawn in the focused entry
+		if showCode {
+			a.codeTabs.SelectIndex(0)
+			a.mainWin.Canvas().Focus(a.convertedScriptCode)
+		} else {
+			a.mainWin.Canvas().Focus(a.rawScriptData)
+		}
+		return
+	}
+}
+
+// highlightLine moves the cursor of the entry to the start of a line and selects the line,
+// the same way as pressing Shift+End there
+func highlightLine(entry *widget.Entry, line int) {
+	line = max(min(line, strings.Count(entry.Text, "\n")), 0)
+	// Home ends the previous selection
+	entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyHome})
+	entry.CursorRow = line
+	entry.CursorColumn = 0
+	entry.KeyDown(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+	entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyEnd})
+	entry.KeyUp(&fyne.KeyEvent{Name: desktop.KeyShiftLeft})
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// opcodeSpecFilesPreference stores the opcode spec files that are loaded when the viewer starts
+const opcodeSpecFilesPreference = "opcodeSpecFiles"
+
+// loadSavedOpcodeSpecFiles loads the opcode spec files that were loaded in the previous sessions
+func (a *App) loadSavedOpcodeSpecFiles() {
+	for _, filename := range a.app.Preferences().StringList(opcodeSpecFilesPreference) {
+		if err := fileio.LoadOpcodeSpecFile(filename); err != nil {
+			fyne.LogError("Failed to load opcode spec file", err)
+		}
+	}
+}
+
+// openOpcodeSpecFileDialog replaces the built-in opcode definitions with those of a JSON or TOML
+// spec file and remembers the file for the next sessions
+func (a *App) openOpcodeSpecFileDialog() {
+	dialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.mainWin)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		reader.Close()
+
+		filename := reader.URI().Path()
+		if err := fileio.LoadOpcodeSpecFile(filename); err != nil {
+			dialog.ShowError(err, a.mainWin)
+			return
+		}
+		preferences := a.app.Preferences()
+		preferences.SetStringList(opcodeSpecFilesPreference, append(preferences.StringList(opcodeSpecFilesPreference), filename))
+		a.reopenRoom()
+	}, a.mainWin)
+	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".toml", ".json"}))
+	dialog.Show()
+}
+
+// resetOpcodeSpecs goes back to the built-in opcode definitions
+func (a *App) resetOpcodeSpecs() {
+	fileio.ResetOpcodeSpecs()
+	a.app.Preferences().RemoveValue(opcodeSpecFilesPreference)
+	a.reopenRoom()
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// strictParsingPreference stores whether rooms with errors are rejected instead of loaded without the broken parts
+const strictParsingPreference = "strictParsing"
+
+// loadProblemsView creates the tab that lists the problems found while parsing the room
+func (a *App) loadProblemsView() fyne.CanvasObject {
+	a.problemList = widget.NewList(
+		func() int {
+			return len(a.problems)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template Object")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(a.problems[id].String())
+		},
+	)
+	a.problemList.OnSelected = func(id widget.ListItemID) {
+		a.showProblem(a.problems[id])
+	}
+	return a.problemList
+}
+
+// setProblems lists the problems of the opened room
+func (a *App) setProblems(diagnostics []*fileio.Diagnostic) {
+	a.problems = diagnostics
+	a.problemList.UnselectAll()
+	a.problemList.Refresh()
+}
+
+// showProblem opens the script file of a problem and moves the cursor behind the last
+// instruction that could be parsed, where the broken instruction starts
+func (a *App) showProblem(diagnostic *fileio.Diagnostic) {
+	var filename string
+	switch {
+	case diagnostic.Section == fileio.SectionInitScript:
+		filename = "init.scd"
+	case diagnostic.Section == fileio.SectionRoomScript && diagnostic.Function != fileio.NoFunction:
+		filename = fmt.Sprintf("sub%d.scd", diagnostic.Function)
+	default:
+		return
+	}
+	a.selectScriptLine(filename, len(a.scriptProgramCounters[filename]), false)
+}
+
+// problemSummary is the number of problems shown in the status bar
+func (a *App) problemSummary() string {
+	if a.rdtOutput == nil {
+		return ""
+	}
+	errors, warnings := fileio.CountDiagnostics(a.rdtOutput.Diagnostics)
+	if errors == 0 && warnings == 0 {
+		return "No problems"
+	}
+	return fmt.Sprintf("%d errors, %d warnings", errors, warnings)
+}
+
+// toggleStrictParsing switches between rejecting rooms with errors and loading them without
+// the broken parts, and loads the opened room again
+func (a *App) toggleStrictParsing(menuItem *fyne.MenuItem) {
+	fileio.StrictParsing = !fileio.StrictParsing
+	a.app.Preferences().SetBool(strictParsingPreference, fileio.StrictParsing)
+	menuItem.Checked = fileio.StrictParsing
+	a.mainWin.MainMenu().Refresh()
+	a.reopenRoom()
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/catalogue"
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// findFlagReferences shows every room in the directory of the opened room that sets,
+// clears, flips or tests the flag of the SetBit or CheckBit on the line of the cursor
+func (a *App) findFlagReferences() {
+	flag, ok := a.cursorFlag()
+	if !ok {
+		dialog.ShowInformation("Find Flag References", "Move the cursor to a SetBit or CheckBit line first.", a.mainWin)
+		return
+	}
+
+	// Index the rooms next to the opened room once, or all rooms of its disc image
+	dir := filepath.Dir(a.roomFilename)
+	if image, _, ok := fileio.SplitDiscRoomPath(a.roomFilename); ok {
+		dir = image
+	}
+	if a.flagIndex == nil || a.flagIndexDir != dir {
+		rooms, _ := catalogue.LoadRooms(dir)
+		a.flagIndex = catalogue.NewFlagIndex(rooms)
+		a.flagIndexDir = dir
+	}
+	references := a.flagIndex.Find(flag)
+
+	var referenceDialog dialog.Dialog
+	list := widget.NewList(
+		func() int {
+			return len(references)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("Template Object")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(catalogue.FormatReference(references[id]))
+		},
+	)
+	// References in the opened room can be shown in the script
+	list.OnSelected = func(id widget.ListItemID) {
+		source := references[id].Source
+		if filepath.Clean(source.Filename) == filepath.Clean(a.roomFilename) {
+			referenceDialog.Hide()
+			a.selectScriptLine(source.ScriptFile, source.Line-1, true)
+		}
+	}
+
+	title := fmt.Sprintf("References of flag %s in %s", flag, dir)
+	referenceDialog = dialog.NewCustom(title, "Close", list, a.mainWin)
+	referenceDialog.Resize(fyne.NewSize(800, 400))
+	referenceDialog.Show()
+}
+
+// cursorFlag returns the flag of the SetBit or CheckBit instruction on the line of the cursor
+func (a *App) cursorFlag() (catalogue.Flag, bool) {
+	location, ok := a.cursorLocation()
+	if !ok || a.rdtOutput == nil {
+		return catalogue.Flag{}, false
+	}
+	script := a.rdtOutput.RoomScriptData.ScriptData
+	if location.Init {
+		script = a.rdtOutput.InitScriptData.ScriptData
+	}
+	instruction, exists := script.InstructionAt(location.ProgramCounter)
+	if !exists {
+		return catalogue.Flag{}, false
+	}
+
+	switch instr := instruction.Decoded().(type) {
+	case fileio.ScriptInstrSetBit:
+		return catalogue.Flag{BitArray: instr.BitArray, BitNumber: instr.BitNumber}, true
+	case fileio.ScriptInstrCheckBitTest:
+		return catalogue.Flag{BitArray: instr.BitArray, BitNumber: instr.BitNumber}, true
+	}
+	return catalogue.Flag{}, false
+}
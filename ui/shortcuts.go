@@ -3,6 +3,8 @@ package ui
 import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/driver/desktop"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/interpreter"
 )
 
 func (a *App) loadKeyboardShortcuts() {
@@ -13,6 +15,12 @@ func (a *App) loadKeyboardShortcuts() {
 		Modifier: a.mainModKey,
 	}, func(shortcut fyne.Shortcut) { a.openFileDialog() })
 
+	// ctrl+r to find the references of the flag under the cursor
+	a.mainWin.Canvas().AddShortcut(&desktop.CustomShortcut{
+		KeyName:  fyne.KeyR,
+		Modifier: a.mainModKey,
+	}, func(shortcut fyne.Shortcut) { a.findFlagReferences() })
+
 	// ctrl+q to quit application
 	a.mainWin.Canvas().AddShortcut(&desktop.CustomShortcut{
 		KeyName:  fyne.KeyQ,
@@ -26,6 +34,15 @@ func (a *App) loadKeyboardShortcuts() {
 			if len(a.mainWin.Canvas().Overlays().List()) > 0 {
 				a.mainWin.Canvas().Overlays().Top().Hide()
 			}
+		// debugger keys
+		case fyne.KeyF5:
+			a.runDebugger((*interpreter.Interpreter).Continue)
+		case fyne.KeyF9:
+			a.toggleBreakpoint()
+		case fyne.KeyF10:
+			a.runDebugger((*interpreter.Interpreter).StepOver)
+		case fyne.KeyF11:
+			a.runDebugger((*interpreter.Interpreter).Step)
 		}
 	})
 }
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+
+	"github.com/OpenBiohazard2/Bio2ScriptViewer/fileio"
+)
+
+// symbolFilesPreference stores the symbol files that are loaded when the viewer starts
+const symbolFilesPreference = "symbolFiles"
+
+// loadSavedSymbolFiles loads the symbol files that were loaded in the previous sessions
+func (a *App) loadSavedSymbolFiles() {
+	for _, filename := range a.app.Preferences().StringList(symbolFilesPreference) {
+		if err := fileio.LoadSymbolFile(filename); err != nil {
+			fyne.LogError("Failed to load symbol file", err)
+		}
+	}
+}
+
+// openSymbolFileDialog adds the names of a JSON or TOML symbol file to the built-in names
+// and remembers the file for the next sessions
+func (a *App) openSymbolFileDialog() {
+	dialog := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, a.mainWin)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		reader.Close()
+
+		filename := reader.URI().Path()
+		if err := fileio.LoadSymbolFile(filename); err != nil {
+			dialog.ShowError(err, a.mainWin)
+			return
+		}
+		preferences := a.app.Preferences()
+		preferences.SetStringList(symbolFilesPreference, append(preferences.StringList(symbolFilesPreference), filename))
+		a.reopenRoom()
+	}, a.mainWin)
+	dialog.SetFilter(storage.NewExtensionFileFilter([]string{".toml", ".json"}))
+	dialog.Show()
+}
+
+// resetSymbols goes back to the built-in names
+func (a *App) resetSymbols() {
+	fileio.Symbols = fileio.DefaultSymbols()
+	a.app.Preferences().RemoveValue(symbolFilesPreference)
+	a.reopenRoom()
+}
+
+// reopenRoom loads the opened room again so that the scripts are shown with the current names
+func (a *App) reopenRoom() {
+	if a.roomFilename == "" {
+		return
+	}
+	if err := a.openRoomFile(a.roomFilename); err != nil {
+		dialog.ShowError(err, a.mainWin)
+	}
+}